@@ -112,7 +112,8 @@ func (v *Point) Set(u *Point) *Point {
 // Encoding.
 
 // Bytes returns the canonical 32-byte encoding of v, according to RFC 8032,
-// Section 5.1.2.
+// Section 5.1.2. The encoding is computed from the affine coordinates, so it
+// does not depend on the internal projective representation of v.
 func (v *Point) Bytes() []byte {
 	// This function is outlined to make the allocations inline in the caller
 	// rather than happen on the heap.
@@ -381,7 +382,9 @@ func (v *Point) Negate(p *Point) *Point {
 	return v
 }
 
-// Equal returns 1 if v is equivalent to u, and 0 otherwise.
+// Equal returns 1 if v is equivalent to u, and 0 otherwise. Points are
+// compared by cross-multiplying their projective coordinates, so the result
+// does not depend on their internal representation.
 func (v *Point) Equal(u *Point) int {
 	checkInitialized(v, u)
 
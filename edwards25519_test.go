@@ -5,11 +5,13 @@
 package edwards25519
 
 import (
+	"bytes"
 	"encoding/hex"
 	"os"
 	"reflect"
 	"strings"
 	"testing"
+	"testing/quick"
 
 	"filippo.io/edwards25519/field"
 )
@@ -89,6 +91,83 @@ func TestAddSubNegOnBasePoint(t *testing.T) {
 	checkOnCurve(t, checkLhs, checkRhs, Bneg)
 }
 
+func TestDoubleNonAffineZ(t *testing.T) {
+	// projP1xP1.Double uses the dbl-2008-hwcd formulas, which don't require
+	// Z = 1. Check it on points with Z != 1, like the ones produced by the
+	// scalar multiplication ladders.
+	doubleMatchesAdd := func(x Scalar) bool {
+		var p, check, sum Point
+		p.ScalarMult(&x, B)
+		check.fromP1xP1(new(projP1xP1).Double(new(projP2).FromP3(&p)))
+		sum.Add(&p, &p)
+		checkOnCurve(t, &check, &sum)
+		return check.Equal(&sum) == 1
+	}
+	if err := quick.Check(doubleMatchesAdd, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEqualDifferentZ(t *testing.T) {
+	equalAcrossRepresentations := func(x Scalar) bool {
+		// 2P computed as P + P and as 3P - P lands on different Z values.
+		var p, a, b Point
+		p.ScalarBaseMult(&x)
+		a.Add(&p, &p)
+		b.Add(&a, &p)
+		b.Subtract(&b, &p)
+		checkOnCurve(t, &a, &b)
+		if x.Equal(NewScalar()) == 0 && a.z.Equal(&b.z) == 1 {
+			return false
+		}
+		return a.Equal(&b) == 1 && b.Equal(&a) == 1 &&
+			bytes.Equal(a.Bytes(), b.Bytes())
+	}
+	if err := quick.Check(equalAcrossRepresentations, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCachedCondNeg(t *testing.T) {
+	condNegMatchesNegate := func(x Scalar) bool {
+		var p, pNeg Point
+		p.ScalarMult(&x, B)
+		pNeg.Negate(&p)
+
+		var c, cNeg projCached
+		c.FromP3(&p)
+		cNeg.FromP3(&pNeg)
+		if c.CondNeg(0); !projCachedEqual(&c, new(projCached).FromP3(&p)) {
+			return false
+		}
+		if c.CondNeg(1); !projCachedEqual(&c, &cNeg) {
+			return false
+		}
+
+		var a, aNeg affineCached
+		a.FromP3(&p)
+		aNeg.FromP3(&pNeg)
+		if a.CondNeg(0); !affineCachedEqual(&a, new(affineCached).FromP3(&p)) {
+			return false
+		}
+		a.CondNeg(1)
+		return affineCachedEqual(&a, &aNeg)
+	}
+	if err := quick.Check(condNegMatchesNegate, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+}
+
+func projCachedEqual(a, b *projCached) bool {
+	return a.YplusX.Equal(&b.YplusX) == 1 && a.YminusX.Equal(&b.YminusX) == 1 &&
+		a.Z.Equal(&b.Z) == 1 && a.T2d.Equal(&b.T2d) == 1
+}
+
+func affineCachedEqual(a, b *affineCached) bool {
+	return a.YplusX.Equal(&b.YplusX) == 1 && a.YminusX.Equal(&b.YminusX) == 1 &&
+		a.T2d.Equal(&b.T2d) == 1
+}
+
 func TestComparable(t *testing.T) {
 	if reflect.TypeOf(Point{}).Comparable() {
 		t.Error("Point is unexpectedly comparable")
@@ -291,6 +370,12 @@ func TestAllocations(t *testing.T) {
 		s := NewScalar()
 		testAllocationsSink ^= s.Bytes()[0]
 		testAllocationsSink ^= p.Bytes()[0]
+		x, y := p.AffineBytes()
+		testAllocationsSink ^= x[0] ^ y[0]
+		if ValidPointEncoding(generator.Bytes()) {
+			testAllocationsSink ^= 1
+		}
+		testAllocationsSink ^= p.ScalarMultBytesOut(s, generator)[0]
 	}); allocs > 0 {
 		t.Errorf("expected zero allocations, got %0.1v", allocs)
 	}
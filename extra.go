@@ -8,7 +8,17 @@ package edwards25519
 // upstream crypto/ed25519/internal/edwards25519 package.
 
 import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"math/bits"
 
 	"filippo.io/edwards25519/field"
 )
@@ -52,6 +62,11 @@ func (v *Point) SetExtendedCoordinates(X, Y, Z, T *field.Element) (*Point, error
 
 func isOnCurve(X, Y, Z, T *field.Element) bool {
 	var lhs, rhs field.Element
+	// Z = 0 doesn't represent a point, and would satisfy the equations below
+	// for X = Y = T = 0.
+	if Z.Equal(&lhs) == 1 {
+		return false
+	}
 	XX := new(field.Element).Square(X)
 	YY := new(field.Element).Square(Y)
 	ZZ := new(field.Element).Square(Z)
@@ -72,6 +87,211 @@ func isOnCurve(X, Y, Z, T *field.Element) bool {
 	return lhs.Equal(&rhs) == 1
 }
 
+// NewPointFromCoordinates returns a new Point set to (X:Y:Z:T) in extended
+// coordinates, where X, Y, Z, and T are canonical 32-byte little-endian field
+// element encodings. As with SetExtendedCoordinates, the coordinates must
+// satisfy the curve equation and the extended coordinates invariant
+// XY = ZT, and Z must not be zero.
+//
+// If any encoding is not canonical, or the coordinates don't represent a valid
+// point on the curve, NewPointFromCoordinates returns nil and an error.
+func NewPointFromCoordinates(X, Y, Z, T []byte) (*Point, error) {
+	var e [4]*field.Element
+	for i, b := range [][]byte{X, Y, Z, T} {
+		fe, err := feSetCanonicalBytes(b)
+		if err != nil {
+			return nil, fmt.Errorf("edwards25519: decoding %c coordinate: %w", "XYZT"[i], err)
+		}
+		e[i] = fe
+	}
+	return new(Point).SetExtendedCoordinates(e[0], e[1], e[2], e[3])
+}
+
+// FillBytes writes the canonical 32-byte encoding of v, as returned by Bytes,
+// into dst, and returns dst[:32]. If dst is shorter than 32 bytes, FillBytes
+// returns nil and an error. Unlike Bytes, FillBytes never allocates.
+func (v *Point) FillBytes(dst []byte) ([]byte, error) {
+	if len(dst) < 32 {
+		return nil, errors.New("edwards25519: FillBytes buffer is too short")
+	}
+	return v.bytes((*[32]byte)(dst[:32])), nil
+}
+
+// SetBytesWithSign sets v = x like SetBytes, and additionally returns 1 if
+// the recovered x-coordinate is negative and 0 otherwise. If x does not
+// represent a valid point on the curve, SetBytesWithSign returns an error and
+// the receiver is unchanged.
+//
+// The returned sign matches the sign bit of the encoding, except for the
+// non-canonical encodings of points with x = 0 and the sign bit set.
+func (v *Point) SetBytesWithSign(x []byte) (negativeX int, err error) {
+	if _, err := v.SetBytes(x); err != nil {
+		return 0, err
+	}
+	// SetBytes sets Z = 1, so v.x is the affine x-coordinate.
+	return v.x.IsNegative(), nil
+}
+
+// SetAffineBytes sets v = (x, y), where x and y are the canonical 32-byte
+// little-endian encodings of the affine coordinates of a point.
+//
+// If x or y are not canonical encodings, or if (x, y) is not a point on the
+// curve, SetAffineBytes returns nil and an error and the receiver is unchanged.
+// Otherwise, SetAffineBytes returns v.
+func (v *Point) SetAffineBytes(x, y []byte) (*Point, error) {
+	X, err := feSetCanonicalBytes(x)
+	if err != nil {
+		return nil, err
+	}
+	Y, err := feSetCanonicalBytes(y)
+	if err != nil {
+		return nil, err
+	}
+	T := new(field.Element).Multiply(X, Y)
+	if !isOnCurve(X, Y, feOne, T) {
+		return nil, errors.New("edwards25519: invalid point coordinates")
+	}
+	v.x.Set(X)
+	v.y.Set(Y)
+	v.z.One()
+	v.t.Set(T)
+	return v, nil
+}
+
+// IsOnCurveBytes reports whether (x, y) is a point on the curve, where x and y
+// are the canonical 32-byte little-endian encodings of its affine coordinates.
+// If x or y are not canonical encodings, IsOnCurveBytes returns an error.
+func IsOnCurveBytes(x, y []byte) (bool, error) {
+	X, err := feSetCanonicalBytes(x)
+	if err != nil {
+		return false, err
+	}
+	Y, err := feSetCanonicalBytes(y)
+	if err != nil {
+		return false, err
+	}
+	T := new(field.Element).Multiply(X, Y)
+	return isOnCurve(X, Y, feOne, T), nil
+}
+
+// feSetCanonicalBytes decodes x as a field element, rejecting encodings that
+// are not reduced modulo 2^255 - 19 or that have the most significant bit set.
+func feSetCanonicalBytes(x []byte) (*field.Element, error) {
+	v, err := new(field.Element).SetBytes(x)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(v.Bytes(), x) != 1 {
+		return nil, errors.New("edwards25519: non-canonical field element encoding")
+	}
+	return v, nil
+}
+
+// AffineBytes returns the canonical 32-byte little-endian encodings of the
+// affine coordinates x = X/Z and y = Y/Z of v.
+func (v *Point) AffineBytes() (x, y []byte) {
+	// This function is outlined to make the allocations inline in the caller
+	// rather than happen on the heap.
+	var buf [64]byte
+	return v.affineBytes(&buf)
+}
+
+func (v *Point) affineBytes(buf *[64]byte) (x, y []byte) {
+	checkInitialized(v)
+
+	var zInv, xx, yy field.Element
+	zInv.Invert(&v.z)        // zInv = 1 / Z
+	xx.Multiply(&v.x, &zInv) // x = X / Z
+	yy.Multiply(&v.y, &zInv) // y = Y / Z
+
+	copy(buf[:32], xx.Bytes())
+	copy(buf[32:], yy.Bytes())
+	return buf[:32:32], buf[32:]
+}
+
+// Select sets v to a if cond == 1, and to b if cond == 0, and returns v. The
+// selection is performed in constant time.
+func (v *Point) Select(a, b *Point, cond int) *Point {
+	checkInitialized(a, b)
+	v.x.Select(&a.x, &b.x, cond)
+	v.y.Select(&a.y, &b.y, cond)
+	v.z.Select(&a.z, &b.z, cond)
+	v.t.Select(&a.t, &b.t, cond)
+	return v
+}
+
+// Blind multiplies all the extended coordinates of v by a random nonzero field
+// element read from rand. The point represented by v is unchanged, but its
+// internal representation is randomized. If reading from rand fails, Blind
+// returns an error and v is unchanged.
+//
+// Blind is a hardening measure against side channels that depend on the
+// representation of an input point. It doesn't guarantee any side channel
+// resistance by itself.
+func (v *Point) Blind(rand io.Reader) error {
+	checkInitialized(v)
+	var buf [64]byte
+	var r field.Element
+	for {
+		if _, err := io.ReadFull(rand, buf[:]); err != nil {
+			return fmt.Errorf("edwards25519: reading blinding factor: %w", err)
+		}
+		r.SetWideBytes(buf[:])
+		if r.Equal(new(field.Element).Zero()) == 0 {
+			break
+		}
+	}
+	v.x.Multiply(&v.x, &r)
+	v.y.Multiply(&v.y, &r)
+	v.z.Multiply(&v.z, &r)
+	v.t.Multiply(&v.t, &r)
+	return nil
+}
+
+// CanonicalEncodingRoundTrips returns whether decoding the canonical encoding
+// of v produces a point equal to v. This is always true for points produced by
+// this package, and is meant as an invariant check in tests.
+func (v *Point) CanonicalEncodingRoundTrips() bool {
+	var buf [32]byte
+	var w Point
+	if _, err := w.SetBytes(v.bytes(&buf)); err != nil {
+		return false
+	}
+	return w.Equal(v) == 1
+}
+
+// NegBytes returns the canonical 32-byte encoding of -v, without modifying v.
+//
+// Negation only changes the sign of the x coordinate, so the result differs
+// from v.Bytes() only in the sign bit. The exception is when x is zero, as for
+// the identity, which is its own negation and keeps a sign bit of zero.
+func (v *Point) NegBytes() []byte {
+	// This function is outlined to make the allocations inline in the caller
+	// rather than happen on the heap.
+	var buf [32]byte
+	return v.negBytes(&buf)
+}
+
+func (v *Point) negBytes(buf *[32]byte) []byte {
+	checkInitialized(v)
+
+	var zInv, x, y field.Element
+	zInv.Invert(&v.z)       // zInv = 1 / Z
+	x.Multiply(&v.x, &zInv) // x = X / Z
+	y.Multiply(&v.y, &zInv) // y = Y / Z
+	x.Negate(&x)
+
+	out := copyFieldElement(buf, &y)
+	out[31] |= byte(x.IsNegative() << 7)
+	return out
+}
+
+// String returns the canonical encoding of v in hex, wrapped as "Point(...)"
+// to tell it apart from a Scalar, for debugging purposes.
+func (v *Point) String() string {
+	return "Point(" + hex.EncodeToString(v.Bytes()) + ")"
+}
+
 // BytesMontgomery converts v to a point on the birationally-equivalent
 // Curve25519 Montgomery curve, and returns its canonical 32 bytes encoding
 // according to RFC 7748.
@@ -118,6 +338,98 @@ func (v *Point) MultByCofactor(p *Point) *Point {
 	return v.fromP1xP1(&result)
 }
 
+// DivByCofactor sets v = 8^-1 * q, where 8^-1 is the inverse of the cofactor
+// modulo l, and returns v. It is the inverse of MultByCofactor only for points
+// in the prime-order subgroup: for other points, the small-order component of
+// the result is not related to that of q by a division.
+func (v *Point) DivByCofactor(q *Point) *Point {
+	return v.ScalarMult(&scInvEight, q)
+}
+
+// scInvEight is 8^-1 mod l.
+var scInvEight = Scalar{[32]byte{
+	0x79, 0x2f, 0xdc, 0xe2, 0x29, 0xe5, 0x06, 0x61, 0xd0, 0xda, 0x1c, 0x7d,
+	0xb3, 0x9d, 0xd3, 0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x06,
+}}
+
+// EqualUpToCofactor returns 1 if 8 * v == 8 * u, that is, if v and u differ
+// only by a point of small order, and 0 otherwise. Its execution time is
+// independent of the inputs.
+func (v *Point) EqualUpToCofactor(u *Point) int {
+	checkInitialized(v, u)
+	diff := new(Point).Subtract(v, u)
+	return diff.MultByCofactor(diff).IsIdentity()
+}
+
+// ValidPointEncoding returns whether b is a valid point encoding, that is,
+// whether SetBytes would accept it. Like SetBytes, it accepts non-canonical
+// encodings of valid points. ValidPointEncoding does not allocate.
+func ValidPointEncoding(b []byte) bool {
+	var p Point
+	_, err := p.SetBytes(b)
+	return err == nil
+}
+
+// ConstantTimeEqualEncoding returns 1 if a and b are equal 32-byte encodings,
+// and 0 otherwise, including if either is not 32 bytes long. The time taken
+// depends on the lengths of a and b, but not on their contents.
+//
+// Note that a point has exactly one canonical encoding, but SetBytes also
+// accepts a few non-canonical ones, so two different encodings may decode to
+// the same point. Use Point.Equal to compare decoded points.
+func ConstantTimeEqualEncoding(a, b []byte) int {
+	if len(a) != 32 || len(b) != 32 {
+		return 0
+	}
+	return subtle.ConstantTimeCompare(a, b)
+}
+
+// IsLowOrderEncoding returns whether b is a valid encoding of one of the eight
+// points of order dividing the cofactor, that is, of a point P such that
+// 8 * P is the identity. It returns false if b is not a valid point encoding.
+//
+// All encodings accepted by SetBytes are recognized, including non-canonical
+// ones, so it can be used to reject low-order public keys regardless of how
+// they are encoded.
+func IsLowOrderEncoding(b []byte) bool {
+	p, err := new(Point).SetBytes(b)
+	if err != nil {
+		return false
+	}
+	p.MultByCofactor(p)
+	return p.Equal(identity) == 1
+}
+
+// IsIdentity returns 1 if v is the identity point, and 0 otherwise. Some
+// protocols must reject the identity as a public key, since it corresponds to
+// a zero private key. Unlike SmallOrderFactor, IsIdentity matches only the
+// identity, and its execution time is independent of v.
+func (v *Point) IsIdentity() int {
+	checkInitialized(v)
+	// In projective coordinates, the identity is (0 : Z : Z : 0).
+	var zero field.Element
+	return v.x.Equal(&zero) & v.y.Equal(&v.z)
+}
+
+// SmallOrderFactor returns the order of v if it divides the cofactor, that is
+// 1, 2, 4, or 8, and 0 otherwise, including for all points with a nonzero
+// prime-order component.
+//
+// Execution time depends on the input, so SmallOrderFactor must not be used on
+// secret values.
+func (v *Point) SmallOrderFactor() int {
+	checkInitialized(v)
+	p := new(Point).Set(v)
+	for order := 1; order <= 8; order *= 2 {
+		if p.Equal(identity) == 1 {
+			return order
+		}
+		p.Add(p, p)
+	}
+	return 0
+}
+
 // Given k > 0, set s = s**(2*i).
 func (s *Scalar) pow2k(k int) {
 	for i := 0; i < k; i++ {
@@ -231,9 +543,749 @@ func (s *Scalar) Invert(t *Scalar) *Scalar {
 	return s
 }
 
+// Order returns the prime order of the edwards25519 group,
+//
+//	l = 2^252 + 27742317777372353535851937790883648493
+//
+// as a new big.Int, which the caller is free to modify.
+func Order() *big.Int {
+	l, _ := new(big.Int).SetString("27742317777372353535851937790883648493", 10)
+	return l.Add(l, new(big.Int).Lsh(big.NewInt(1), 252))
+}
+
+// Cofactor returns the cofactor of the edwards25519 curve, 8. The order of the
+// full curve group is 8 * l, where l is the prime returned by Order.
+func Cofactor() int {
+	return 8
+}
+
+// CurveD returns the canonical 32-byte little-endian encoding of the constant
+// d = -121665/121666 in the curve equation -x^2 + y^2 = 1 + dx^2y^2.
+func CurveD() []byte {
+	return d.Bytes()
+}
+
+// CurveDField returns a new field.Element set to the curve constant d, as
+// encoded by CurveD.
+func CurveDField() *field.Element {
+	return new(field.Element).Set(d)
+}
+
+// CurveTwoDField returns a new field.Element set to 2 * d, which appears in the
+// addition formulas for extended coordinates.
+func CurveTwoDField() *field.Element {
+	return new(field.Element).Set(d2)
+}
+
+// One returns a new Scalar set to the multiplicative identity, 1. Use
+// NewScalar for the additive identity.
+func One() *Scalar {
+	return new(Scalar).Set(&scOne)
+}
+
+// String returns the canonical little-endian encoding of s in hex, for
+// debugging purposes.
+func (s *Scalar) String() string {
+	return hex.EncodeToString(s.Bytes())
+}
+
+// SetBytesModOrder sets s = x mod l, where x is a 32-byte little-endian
+// integer, and returns s. If x is not of the right length, SetBytesModOrder
+// returns nil and an error, and the receiver is unchanged.
+//
+// Unlike SetCanonicalBytes, SetBytesModOrder accepts values greater than or
+// equal to l and reduces them. Note that the result is not uniformly
+// distributed even if x is, so SetUniformBytes should be used to derive
+// scalars from random or hashed values where possible.
+func (s *Scalar) SetBytesModOrder(x []byte) (*Scalar, error) {
+	if len(x) != 32 {
+		return nil, errors.New("edwards25519: invalid SetBytesModOrder input length")
+	}
+	var wideBytes [64]byte
+	copy(wideBytes[:], x)
+	scReduce(&s.s, &wideBytes)
+	return s, nil
+}
+
+// SetBigEndianModOrder sets s = b mod l, where b is a big-endian integer of
+// any length, and returns s. An empty b is zero.
+//
+// Inputs longer than 64 bytes are reduced 64 bytes at a time, folding the
+// running result into the next chunk by multiplying it by 2^512 mod l, so
+// there is no length limit.
+func (s *Scalar) SetBigEndianModOrder(b []byte) *Scalar {
+	var acc, chunk Scalar
+	first := len(b) % 64
+	if first == 0 && len(b) > 0 {
+		first = 64
+	}
+	for len(b) > 0 {
+		var wideBytes [64]byte
+		n := 64
+		if first != 0 {
+			n, first = first, 0
+		}
+		for i := 0; i < n; i++ {
+			wideBytes[i] = b[n-1-i]
+		}
+		chunk.SetUniformBytes(wideBytes[:])
+		acc.MultiplyAdd(&acc, &scTwo512, &chunk)
+		b = b[n:]
+	}
+	return s.Set(&acc)
+}
+
+// scTwo512 is 2^512 mod l.
+var scTwo512 = Scalar{[32]byte{
+	0x01, 0x0f, 0x9c, 0x44, 0xe3, 0x11, 0x06, 0xa4, 0x47, 0x93, 0x85, 0x68,
+	0xa7, 0x1b, 0x0e, 0xd0, 0x65, 0xbe, 0xf5, 0x17, 0xd2, 0x73, 0xec, 0xce,
+	0x3d, 0x9a, 0x30, 0x7c, 0x1b, 0x41, 0x99, 0x03,
+}}
+
+// SetFromHash sets s to the first 64 bytes of h.Sum(nil) reduced modulo l, as
+// with SetUniformBytes, and returns s. It does not reset h. If h produces
+// fewer than 64 bytes, SetFromHash returns nil and an error, and the receiver
+// is unchanged.
+//
+// SetFromHash can be used to derive a Fiat-Shamir challenge from a transcript
+// hashed with a 64-byte output hash such as SHA-512.
+func (s *Scalar) SetFromHash(h hash.Hash) (*Scalar, error) {
+	if h.Size() < 64 {
+		return nil, errors.New("edwards25519: SetFromHash hash output is too short")
+	}
+	var buf [64]byte
+	sum := h.Sum(buf[:0])
+	return s.SetUniformBytes(sum[:64])
+}
+
+// Zero sets s = 0 by overwriting its internal representation, and returns s.
+//
+// Zero is the recommended way to wipe a secret Scalar before it goes out of
+// scope. Note that it can't reach copies of the value made elsewhere, for
+// example by passing Scalar values rather than pointers, or by the runtime
+// when moving goroutine stacks.
+func (s *Scalar) Zero() *Scalar {
+	for i := range s.s {
+		s.s[i] = 0
+	}
+	return s
+}
+
+// FillBytes writes the canonical 32-byte little-endian encoding of s into
+// dst, and returns dst[:32]. If dst is shorter than 32 bytes, FillBytes returns
+// nil and an error. Unlike Bytes, FillBytes never allocates.
+func (s *Scalar) FillBytes(dst []byte) ([]byte, error) {
+	if len(dst) < 32 {
+		return nil, errors.New("edwards25519: FillBytes buffer is too short")
+	}
+	copy(dst, s.s[:])
+	return dst[:32], nil
+}
+
+// SetCanonicalBytesBE sets s = x, where x is a 32-byte big-endian encoding of
+// s, and returns s. If x is not a canonical encoding of s, SetCanonicalBytesBE
+// returns nil and an error, and the receiver is unchanged.
+func (s *Scalar) SetCanonicalBytesBE(x []byte) (*Scalar, error) {
+	if len(x) != 32 {
+		return nil, errors.New("edwards25519: invalid SetCanonicalBytesBE input length")
+	}
+	var le [32]byte
+	for i := range le {
+		le[i] = x[31-i]
+	}
+	return s.SetCanonicalBytes(le[:])
+}
+
+// SetPaddedCanonicalBytes sets s = x[:32], where x[:32] is a canonical
+// 32-byte little-endian encoding of s, and all the bytes of x after the first
+// 32 are zero, and returns s. If x is shorter than 32 bytes, if the padding is
+// not all zeroes, or if x[:32] is not canonical, SetPaddedCanonicalBytes
+// returns nil and an error, and the receiver is unchanged.
+func (s *Scalar) SetPaddedCanonicalBytes(x []byte) (*Scalar, error) {
+	if len(x) < 32 {
+		return nil, errors.New("edwards25519: invalid SetPaddedCanonicalBytes input length")
+	}
+	var padding byte
+	for _, b := range x[32:] {
+		padding |= b
+	}
+	if padding != 0 {
+		return nil, errors.New("edwards25519: invalid scalar padding")
+	}
+	return s.SetCanonicalBytes(x[:32])
+}
+
+// BytesBE returns the canonical 32-byte big-endian encoding of s.
+func (s *Scalar) BytesBE() []byte {
+	buf := make([]byte, 32)
+	for i := range buf {
+		buf[i] = s.s[31-i]
+	}
+	return buf
+}
+
+// NegBytes returns the canonical 32-byte little-endian encoding of -s mod l.
+// It is equivalent to new(Scalar).Negate(s).Bytes(), without modifying s.
+func (s *Scalar) NegBytes() []byte {
+	var neg Scalar
+	neg.Negate(s)
+	return neg.Bytes()
+}
+
+// CondNeg sets s = -x mod l if cond == 1, and s = x if cond == 0, and
+// returns s. The selection is performed in constant time.
+func (s *Scalar) CondNeg(x *Scalar, cond int) *Scalar {
+	var neg Scalar
+	neg.Negate(x)
+	m := byte(-cond)
+	for i := range s.s {
+		s.s[i] = x.s[i] ^ (m & (x.s[i] ^ neg.s[i]))
+	}
+	return s
+}
+
+// Cmp compares the canonical values of s and t as integers in [0, l), and
+// returns -1 if s < t, 0 if s == t, and +1 if s > t.
+//
+// Execution time depends on the inputs, so Cmp must not be used on secret
+// values. Use Equal for constant-time equality checks.
+func (s *Scalar) Cmp(t *Scalar) int {
+	for i := len(s.s) - 1; i >= 0; i-- {
+		switch {
+		case s.s[i] > t.s[i]:
+			return 1
+		case s.s[i] < t.s[i]:
+			return -1
+		}
+	}
+	return 0
+}
+
+// IsLowHalf returns 1 if the canonical value of s is in the lower half of
+// [0, l), that is, at most (l - 1) / 2, and 0 otherwise. For every nonzero s,
+// exactly one of s and -s is in the lower half.
+//
+// Execution time depends on the value of s, so IsLowHalf must not be used on
+// secret values.
+func (s *Scalar) IsLowHalf() int {
+	if s.Cmp(&scHalfOrder) > 0 {
+		return 0
+	}
+	return 1
+}
+
+// CanonicalizeLowHalf sets s = -s if s is not in the lower half of [0, l), as
+// defined by IsLowHalf, and returns s.
+//
+// Execution time depends on the value of s, so CanonicalizeLowHalf must not
+// be used on secret values.
+func (s *Scalar) CanonicalizeLowHalf() *Scalar {
+	if s.IsLowHalf() == 0 {
+		s.Negate(s)
+	}
+	return s
+}
+
+// scHalfOrder is (l - 1) / 2.
+var scHalfOrder = Scalar{[32]byte{
+	0xf6, 0xe9, 0x7a, 0x2e, 0x8d, 0x31, 0x09, 0x2c, 0x6b, 0xce, 0x7b, 0x51,
+	0xef, 0x7c, 0x6f, 0x0a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08,
+}}
+
+// IsInvertible returns 1 if s has a multiplicative inverse modulo l, that is
+// if s is not zero, and 0 otherwise. It can be used as a guard before Invert,
+// which returns zero for a zero input.
+func (s *Scalar) IsInvertible() int {
+	return 1 - s.Equal(&scZero)
+}
+
+// SetUniformBytesBatch sets each dst[i] to the i-th 64-byte chunk of src
+// reduced modulo l, as with SetUniformBytes. If len(src) is not 64 *
+// len(dst), SetUniformBytesBatch returns an error and dst is unchanged.
+func SetUniformBytesBatch(dst []*Scalar, src []byte) error {
+	if len(src) != 64*len(dst) {
+		return errors.New("edwards25519: invalid SetUniformBytesBatch input length")
+	}
+	for i := range dst {
+		dst[i].SetUniformBytes(src[64*i : 64*(i+1)])
+	}
+	return nil
+}
+
+// ScalarDotProduct sets out = sum(a[i] * b[i]) mod l. If a and b don't have
+// the same length, ScalarDotProduct returns an error and out is unchanged.
+func ScalarDotProduct(out *Scalar, a, b []*Scalar) error {
+	if len(a) != len(b) {
+		return errors.New("edwards25519: called ScalarDotProduct with different size inputs")
+	}
+	var acc Scalar
+	for i := range a {
+		acc.MultiplyAdd(a[i], b[i], &acc)
+	}
+	out.Set(&acc)
+	return nil
+}
+
+// ScalarProduct sets out = prod(ss[i]) mod l, and returns out. If ss is empty,
+// out is set to one. out may alias any of the elements of ss.
+func ScalarProduct(out *Scalar, ss []*Scalar) *Scalar {
+	acc := scOne
+	for _, s := range ss {
+		acc.Multiply(&acc, s)
+	}
+	return out.Set(&acc)
+}
+
+// MulMany sets s to the product of factors modulo l, and returns s. If no
+// factors are given, s is set to one. s may alias any of the factors.
+func (s *Scalar) MulMany(factors ...*Scalar) *Scalar {
+	return ScalarProduct(s, factors)
+}
+
+// AddAssign sets s = s + x mod l, and returns s.
+func (s *Scalar) AddAssign(x *Scalar) *Scalar {
+	return s.Add(s, x)
+}
+
+// SubAssign sets s = s - x mod l, and returns s.
+func (s *Scalar) SubAssign(x *Scalar) *Scalar {
+	return s.Subtract(s, x)
+}
+
+// MulAssign sets s = s * x mod l, and returns s.
+func (s *Scalar) MulAssign(x *Scalar) *Scalar {
+	return s.Multiply(s, x)
+}
+
+// NewScalarPowers returns the first n powers of x, that is
+// [1, x, x², ..., x^(n-1)], computed with n-1 multiplications. If n is zero
+// or negative, NewScalarPowers returns nil.
+func NewScalarPowers(x *Scalar, n int) []*Scalar {
+	if n <= 0 {
+		return nil
+	}
+	powers := make([]*Scalar, n)
+	for i := range powers {
+		if i == 0 {
+			powers[i] = One()
+			continue
+		}
+		powers[i] = new(Scalar).Multiply(powers[i-1], x)
+	}
+	return powers
+}
+
+// EvalPoly returns the value at x of the polynomial with coefficients coeffs,
+// that is sum(coeffs[i] * x^i), where coeffs[0] is the constant term. It uses
+// Horner's rule, with one multiplication per coefficient. If coeffs is empty,
+// EvalPoly returns zero.
+func EvalPoly(coeffs []*Scalar, x *Scalar) *Scalar {
+	acc := NewScalar()
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		acc.MultiplyAdd(acc, x, coeffs[i])
+	}
+	return acc
+}
+
+// LagrangeCoefficients returns the Lagrange basis coefficients at zero for the
+// set of evaluation points xs, that is, for each i
+//
+//	lambda[i] = prod(xs[j] / (xs[j] - xs[i])) for j != i
+//
+// so that a polynomial f of degree less than len(xs) satisfies
+// f(0) = sum(lambda[i] * f(xs[i])). This is the reconstruction step of Shamir
+// secret sharing. If any two elements of xs are equal, LagrangeCoefficients
+// returns nil and an error.
+//
+// Execution time depends on len(xs) but not on its values.
+func LagrangeCoefficients(xs []*Scalar) ([]*Scalar, error) {
+	n := len(xs)
+	nums := make([]Scalar, n)
+	dens := make([]Scalar, n)
+	for i := range xs {
+		nums[i].Set(&scOne)
+		dens[i].Set(&scOne)
+		for j := range xs {
+			if j == i {
+				continue
+			}
+			var diff Scalar
+			diff.Subtract(xs[j], xs[i])
+			nums[i].Multiply(&nums[i], xs[j])
+			dens[i].Multiply(&dens[i], &diff)
+		}
+	}
+	if n == 0 {
+		return []*Scalar{}, nil
+	}
+
+	// Invert all the denominators at once with Montgomery's trick. The
+	// product of the denominators is zero if and only if two xs are equal.
+	prefix := make([]Scalar, n)
+	prefix[0].Set(&dens[0])
+	for i := 1; i < n; i++ {
+		prefix[i].Multiply(&prefix[i-1], &dens[i])
+	}
+	if prefix[n-1].IsInvertible() == 0 {
+		return nil, errors.New("edwards25519: duplicate value in LagrangeCoefficients input")
+	}
+	var inv Scalar
+	inv.Invert(&prefix[n-1])
+
+	coeffs := make([]*Scalar, n)
+	for i := n - 1; i > 0; i-- {
+		denInv := new(Scalar).Multiply(&inv, &prefix[i-1])
+		coeffs[i] = denInv.Multiply(denInv, &nums[i])
+		inv.Multiply(&inv, &dens[i])
+	}
+	coeffs[0] = inv.Multiply(&inv, &nums[0])
+	return coeffs, nil
+}
+
+// WriteScalars writes the canonical 32-byte encodings of ss to w, back to
+// back, and returns the first error encountered.
+func WriteScalars(w io.Writer, ss []*Scalar) error {
+	for i, s := range ss {
+		if _, err := w.Write(s.s[:]); err != nil {
+			return fmt.Errorf("edwards25519: writing scalar %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ReadScalars reads n canonical 32-byte scalar encodings from r, as written by
+// WriteScalars. It returns an error identifying the first element that could
+// not be read in full or that is not a canonical encoding, or an error if n is
+// negative.
+func ReadScalars(r io.Reader, n int) ([]*Scalar, error) {
+	if n < 0 {
+		return nil, errors.New("edwards25519: negative ReadScalars count")
+	}
+	ss := make([]*Scalar, n)
+	var buf [32]byte
+	for i := range ss {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return nil, fmt.Errorf("edwards25519: reading scalar %d: %w", i, err)
+		}
+		s, err := NewScalar().SetCanonicalBytes(buf[:])
+		if err != nil {
+			return nil, fmt.Errorf("edwards25519: reading scalar %d: %w", i, err)
+		}
+		ss[i] = s
+	}
+	return ss, nil
+}
+
+// ScalarFromLabel returns SHA-512("edwards25519 ScalarFromLabel" || label)
+// reduced modulo l. It is meant for generating readable, reproducible scalar
+// constants, for example in test vectors, and must not be used to derive
+// secrets.
+func ScalarFromLabel(label string) *Scalar {
+	h := sha512.New()
+	h.Write([]byte("edwards25519 ScalarFromLabel"))
+	h.Write([]byte(label))
+	s, _ := NewScalar().SetFromHash(h)
+	return s
+}
+
+// HashPointToScalar returns SHA-512(dst || p.Bytes()) reduced modulo l, for
+// binding a point into a Fiat-Shamir transcript. dst is a domain separation
+// tag, and since the point encoding has a fixed length, any dst unambiguously
+// separates the two inputs.
+func HashPointToScalar(p *Point, dst []byte) *Scalar {
+	var buf [32]byte
+	h := sha512.New()
+	h.Write(dst)
+	h.Write(p.bytes(&buf))
+	s, _ := NewScalar().SetFromHash(h)
+	return s
+}
+
+// SetEd25519Seed sets s to the secret scalar of the Ed25519 private key with
+// the given 32-byte seed, and returns the 32-byte prefix used to derive
+// signing nonces, following the key expansion of RFC 8032, Section 5.1.5. If
+// seed is not of the right length, SetEd25519Seed returns an error, and the
+// receiver is unchanged.
+//
+// The corresponding public key is new(Point).ScalarBaseMult(s).Bytes().
+func (s *Scalar) SetEd25519Seed(seed []byte) (prefix []byte, err error) {
+	if len(seed) != 32 {
+		return nil, errors.New("edwards25519: invalid SetEd25519Seed input length")
+	}
+	h := sha512.Sum512(seed)
+	s.SetBytesWithClamping(h[:32])
+	prefix = make([]byte, 32)
+	copy(prefix, h[32:])
+	return prefix, nil
+}
+
+// SetHashToField sets s to the output of hash_to_field(msg, 1) from RFC 9380,
+// Section 5.2, over the scalar field, and returns s. It uses
+// expand_message_xmd with SHA-512 and the domain separation tag dst, and the
+// parameters m = 1 and L = 48, which is ceil((ceil(log2(l)) + k) / 8) for a
+// security level k of 128 bits.
+//
+// Note that this is not the same as SHA-512 followed by SetUniformBytes.
+func (s *Scalar) SetHashToField(msg, dst []byte) *Scalar {
+	var buf [48]byte
+	expandMessageXMD(buf[:], msg, dst)
+
+	// hash_to_field interprets the output as a big-endian integer.
+	var wideBytes [64]byte
+	for i := range buf {
+		wideBytes[i] = buf[len(buf)-1-i]
+	}
+	s.SetUniformBytes(wideBytes[:])
+	return s
+}
+
+// expandMessageXMD fills out with expand_message_xmd(msg, dst, len(out)) from
+// RFC 9380, Section 5.3.1, instantiated with SHA-512. len(out) must be at most
+// 255 * 64, or expandMessageXMD will panic.
+func expandMessageXMD(out, msg, dst []byte) {
+	const bInBytes, sInBytes = sha512.Size, sha512.BlockSize
+	ell := (len(out) + bInBytes - 1) / bInBytes
+	if ell > 255 {
+		panic("edwards25519: expand_message_xmd output is too long")
+	}
+
+	// Tags longer than 255 bytes are hashed, per Section 5.3.3.
+	if len(dst) > 255 {
+		h := sha512.New()
+		h.Write([]byte("H2C-OVERSIZE-DST-"))
+		h.Write(dst)
+		dst = h.Sum(nil)
+	}
+
+	h := sha512.New()
+	h.Write(make([]byte, sInBytes))
+	h.Write(msg)
+	h.Write([]byte{byte(len(out) >> 8), byte(len(out)), 0})
+	h.Write(dst)
+	h.Write([]byte{byte(len(dst))})
+	b0 := h.Sum(nil)
+
+	bi := make([]byte, bInBytes)
+	for i := 1; i <= ell; i++ {
+		h.Reset()
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(dst)
+		h.Write([]byte{byte(len(dst))})
+		bi = h.Sum(bi[:0])
+		out = out[copy(out, bi):]
+	}
+}
+
+// DeriveScalar returns a scalar derived deterministically from the input key
+// material ikm. It runs HKDF-SHA512 (RFC 5869) with the given salt and info to
+// produce 64 bytes, and reduces them modulo l as with SetUniformBytes.
+//
+// Distinct info strings produce independent scalars from the same ikm.
+func DeriveScalar(ikm, salt, info []byte) *Scalar {
+	// HKDF-Extract. An empty salt is equivalent to a string of zeroes of
+	// the hash length, which is what HMAC does with a short key anyway.
+	extract := hmac.New(sha512.New, salt)
+	extract.Write(ikm)
+	prk := extract.Sum(nil)
+
+	// HKDF-Expand. The output is exactly one hash block, T(1).
+	expand := hmac.New(sha512.New, prk)
+	expand.Write(info)
+	expand.Write([]byte{1})
+	s, _ := NewScalar().SetFromHash(expand)
+	return s
+}
+
+// BytesMany returns the concatenation of the canonical 32-byte encodings of
+// points, as parsed by SetBytesMany.
+func BytesMany(points []*Point) []byte {
+	out := make([]byte, 32*len(points))
+	for i, p := range points {
+		p.bytes((*[32]byte)(out[32*i : 32*(i+1)]))
+	}
+	return out
+}
+
+// SetBytesMany decodes n points from b, which must be the concatenation of n
+// 32-byte encodings, as produced by BytesMany. Each encoding is validated as
+// with SetBytes. If b is not of the right length, or if any encoding is
+// invalid, SetBytesMany returns nil and an error identifying the first invalid
+// encoding.
+func SetBytesMany(n int, b []byte) ([]*Point, error) {
+	if n < 0 || len(b) != 32*n {
+		return nil, errors.New("edwards25519: invalid SetBytesMany input length")
+	}
+	points := make([]*Point, n)
+	for i := range points {
+		p, err := new(Point).SetBytes(b[32*i : 32*(i+1)])
+		if err != nil {
+			return nil, fmt.Errorf("edwards25519: decoding point %d: %w", i, err)
+		}
+		points[i] = p
+	}
+	return points, nil
+}
+
+// Type tags used by MarshalTagged and UnmarshalTagged.
+const (
+	tagScalar = 0x01
+	tagPoint  = 0x02
+)
+
+// MarshalTagged returns the canonical 32-byte encoding of v, which must be a
+// *Scalar or a *Point, prefixed by a one-byte type tag: 0x01 for scalars and
+// 0x02 for points. The result is 33 bytes long and can be decoded without
+// knowing its type in advance by UnmarshalTagged.
+func MarshalTagged(v interface{}) ([]byte, error) {
+	out := make([]byte, 33)
+	switch v := v.(type) {
+	case *Scalar:
+		if v == nil {
+			return nil, errors.New("edwards25519: cannot marshal nil *Scalar")
+		}
+		out[0] = tagScalar
+		copy(out[1:], v.s[:])
+	case *Point:
+		if v == nil {
+			return nil, errors.New("edwards25519: cannot marshal nil *Point")
+		}
+		out[0] = tagPoint
+		v.bytes((*[32]byte)(out[1:]))
+	default:
+		return nil, fmt.Errorf("edwards25519: cannot marshal value of type %T", v)
+	}
+	return out, nil
+}
+
+// UnmarshalTagged decodes a value produced by MarshalTagged, and returns a
+// *Scalar or a *Point depending on its type tag. The encoding following the
+// tag is validated as with Scalar.SetCanonicalBytes or Point.SetBytes.
+func UnmarshalTagged(b []byte) (interface{}, error) {
+	if len(b) != 33 {
+		return nil, errors.New("edwards25519: invalid tagged encoding length")
+	}
+	switch b[0] {
+	case tagScalar:
+		s, err := NewScalar().SetCanonicalBytes(b[1:])
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case tagPoint:
+		p, err := new(Point).SetBytes(b[1:])
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("edwards25519: unknown encoding tag %#02x", b[0])
+	}
+}
+
+// SumPoints sets dst = sum(points[i]), and returns dst. If points is empty,
+// dst is set to the identity. dst may alias any of the elements of points.
+func SumPoints(dst *Point, points []*Point) *Point {
+	acc := NewIdentityPoint()
+	for _, p := range points {
+		acc.Add(acc, p)
+	}
+	return dst.Set(acc)
+}
+
+// PointAccumulator computes the sum of a stream of points. It reuses its
+// internal scratch space across additions, so Add never allocates.
+//
+// The zero value is an empty accumulator, whose sum is the identity.
+type PointAccumulator struct {
+	acc    Point
+	cached projCached
+	result projP1xP1
+	// started is set once the first point is added, since the zero Point
+	// is not a valid identity. The sum itself may still be the identity.
+	started bool
+}
+
+// Add adds p to the running sum, and returns a.
+func (a *PointAccumulator) Add(p *Point) *PointAccumulator {
+	checkInitialized(p)
+	if !a.started {
+		a.acc.Set(p)
+		a.started = true
+		return a
+	}
+	a.cached.FromP3(p)
+	a.result.Add(&a.acc, &a.cached)
+	a.acc.fromP1xP1(&a.result)
+	return a
+}
+
+// Sum returns a new Point set to the sum of all the points added so far. The
+// accumulator is not modified, and more points can be added afterwards.
+func (a *PointAccumulator) Sum() *Point {
+	if !a.started {
+		return NewIdentityPoint()
+	}
+	return new(Point).Set(&a.acc)
+}
+
+// A Transcript accumulates labeled points and scalars, and derives
+// Fiat-Shamir challenge scalars from them.
+//
+// Every element is absorbed into a running SHA-512 hash as its label and its
+// encoding, each prefixed by its length as a 64-bit little-endian integer, so
+// distinct sequences of labels and values never produce the same input. A
+// Transcript must be created with NewTranscript.
+type Transcript struct {
+	h hash.Hash
+}
+
+// NewTranscript returns a new Transcript, bound to the protocol name domain.
+func NewTranscript(domain string) *Transcript {
+	t := &Transcript{h: sha512.New()}
+	t.append("edwards25519 transcript", []byte(domain))
+	return t
+}
+
+// AppendPoint absorbs the canonical encoding of p into the transcript.
+func (t *Transcript) AppendPoint(label string, p *Point) {
+	var buf [32]byte
+	t.append(label, p.bytes(&buf))
+}
+
+// AppendScalar absorbs the canonical encoding of s into the transcript.
+func (t *Transcript) AppendScalar(label string, s *Scalar) {
+	t.append(label, s.s[:])
+}
+
+// ChallengeScalar returns a challenge scalar derived from all the elements
+// absorbed so far and from label. The label is absorbed into the transcript,
+// so successive challenges are independent, even with the same label.
+func (t *Transcript) ChallengeScalar(label string) *Scalar {
+	t.append(label, nil)
+	s, _ := NewScalar().SetFromHash(t.h)
+	return s
+}
+
+func (t *Transcript) append(label string, data []byte) {
+	var l [8]byte
+	binary.LittleEndian.PutUint64(l[:], uint64(len(label)))
+	t.h.Write(l[:])
+	t.h.Write([]byte(label))
+	binary.LittleEndian.PutUint64(l[:], uint64(len(data)))
+	t.h.Write(l[:])
+	t.h.Write(data)
+}
+
 // MultiScalarMult sets v = sum(scalars[i] * points[i]), and returns v.
 //
 // Execution time depends only on the lengths of the two slices, which must match.
+// MultiScalarMult is safe to use with secret scalars. For public inputs,
+// VarTimeMultiScalarMult is about twice as fast.
 func (v *Point) MultiScalarMult(scalars []*Scalar, points []*Point) *Point {
 	if len(scalars) != len(points) {
 		panic("edwards25519: called MultiScalarMult with different size inputs")
@@ -287,7 +1339,8 @@ func (v *Point) MultiScalarMult(scalars []*Scalar, points []*Point) *Point {
 
 // VarTimeMultiScalarMult sets v = sum(scalars[i] * points[i]), and returns v.
 //
-// Execution time depends on the inputs.
+// Execution time depends on the inputs, so VarTimeMultiScalarMult must only be
+// used with public values. Use MultiScalarMult if any of the scalars is secret.
 func (v *Point) VarTimeMultiScalarMult(scalars []*Scalar, points []*Point) *Point {
 	if len(scalars) != len(points) {
 		panic("edwards25519: called VarTimeMultiScalarMult with different size inputs")
@@ -341,3 +1394,326 @@ func (v *Point) VarTimeMultiScalarMult(scalars []*Scalar, points []*Point) *Poin
 	v.fromP2(tmp2)
 	return v
 }
+
+// ScalarMultBytes sets v = k * q, where k is a 32-byte canonical little-endian
+// scalar encoding, and returns v. If k is not a canonical encoding,
+// ScalarMultBytes returns nil and an error, and the receiver is unchanged.
+//
+// The scalar multiplication is performed in constant time, as in ScalarMult.
+func (v *Point) ScalarMultBytes(k []byte, q *Point) (*Point, error) {
+	var s Scalar
+	if _, err := s.SetCanonicalBytes(k); err != nil {
+		return nil, err
+	}
+	return v.ScalarMult(&s, q), nil
+}
+
+// ScalarMultBytesOut sets v = x * q, and returns the canonical encoding of v,
+// as v.ScalarMult(x, q).Bytes() would. Its execution time is independent of x.
+func (v *Point) ScalarMultBytesOut(x *Scalar, q *Point) []byte {
+	// This function is outlined to make the allocations inline in the caller
+	// rather than happen on the heap.
+	var buf [32]byte
+	return v.scalarMultBytesOut(&buf, x, q)
+}
+
+func (v *Point) scalarMultBytesOut(buf *[32]byte, x *Scalar, q *Point) []byte {
+	return v.ScalarMult(x, q).bytes(buf)
+}
+
+// GeneratorMultiples returns [B, 2B, ..., kB], where B is the canonical
+// generator, computed by repeated addition. If k <= 0, GeneratorMultiples
+// returns nil.
+func GeneratorMultiples(k int) []*Point {
+	if k <= 0 {
+		return nil
+	}
+	var g projCached
+	g.FromP3(generator)
+	var result projP1xP1
+	out := make([]*Point, k)
+	out[0] = NewGeneratorPoint()
+	for i := 1; i < k; i++ {
+		result.Add(out[i-1], &g)
+		out[i] = new(Point).fromP1xP1(&result)
+	}
+	return out
+}
+
+// PowerMultiples sets dst[i] = s^(i+1) * base for each element of dst. If dst
+// is empty, PowerMultiples returns an error. base may alias any of the
+// elements of dst.
+//
+// Each multiplication is performed in constant time, as in ScalarMult, but the
+// lookup table for base is computed only once.
+func PowerMultiples(dst []*Point, s *Scalar, base *Point) error {
+	if len(dst) == 0 {
+		return errors.New("edwards25519: called PowerMultiples with empty dst")
+	}
+	checkInitialized(base)
+	var table projLookupTable
+	table.FromP3(base)
+	power := new(Scalar).Set(s)
+	for i := range dst {
+		dst[i].scalarMultTable(power, &table)
+		power.Multiply(power, s)
+	}
+	return nil
+}
+
+// CommitPoly returns sum(coeffs[i] * powersOfTau[i]), the commitment to the
+// polynomial with coefficients coeffs under the reference string powersOfTau.
+// If coeffs and powersOfTau don't have the same length, CommitPoly returns
+// nil and an error.
+//
+// CommitPoly uses VarTimeMultiScalarMult, so its execution time depends on
+// the coefficients, and it must not be used with secret ones. Use
+// MultiScalarMult for secret polynomials.
+func CommitPoly(coeffs []*Scalar, powersOfTau []*Point) (*Point, error) {
+	if len(coeffs) != len(powersOfTau) {
+		return nil, errors.New("edwards25519: called CommitPoly with different size inputs")
+	}
+	return new(Point).VarTimeMultiScalarMult(coeffs, powersOfTau), nil
+}
+
+// NonAdjacentFormChecked returns the width-w non-adjacent form of s, a signed
+// binary representation where each nonzero digit is odd, smaller than 2^(w-1)
+// in absolute value, and followed by at least w-1 zero digits. The digits are
+// in little-endian order, so s = sum(naf[i] * 2^i).
+//
+// If w is not between 2 and 8, NonAdjacentFormChecked returns an error.
+// Execution time depends on the value of s.
+func (s *Scalar) NonAdjacentFormChecked(w uint) ([256]int8, error) {
+	if w < 2 || w > 8 {
+		return [256]int8{}, errors.New("edwards25519: NAF width must be between 2 and 8")
+	}
+	if s.s[31] > 127 {
+		return [256]int8{}, errors.New("edwards25519: scalar has the high bit set")
+	}
+	return s.nonAdjacentForm(w), nil
+}
+
+// VarTimeScalarMult sets v = x * q, and returns v.
+//
+// Execution time depends on the inputs, so VarTimeScalarMult must only be used
+// with public values, for example during signature verification. It is faster
+// than ScalarMult, especially for short scalars. If x is zero or q is the
+// identity, it returns the identity immediately, and if q is the canonical
+// generator, it uses the precomputed tables of ScalarBaseMult.
+func (v *Point) VarTimeScalarMult(x *Scalar, q *Point) *Point {
+	checkInitialized(q)
+	switch {
+	case x.Equal(&scZero) == 1 || q.Equal(identity) == 1:
+		return v.Set(identity)
+	case q.Equal(generator) == 1:
+		return v.ScalarBaseMult(x)
+	}
+
+	// A width-w NAF of an n-bit scalar has about n/(w+1) nonzero digits, and
+	// the table of odd multiples takes 2^(w-2) additions to build. Pick the
+	// width that minimizes the total.
+	n := uint(x.BitLen())
+	w := uint(2)
+	for w < 8 && (1<<(w-1))+n/(w+2) < (1<<(w-2))+n/(w+1) {
+		w++
+	}
+
+	var table nafLookupTable
+	table.FromP3(q, w)
+	naf := x.nonAdjacentForm(w)
+
+	// Find the first nonzero coefficient.
+	i := 255
+	for i >= 0 && naf[i] == 0 {
+		i--
+	}
+
+	multQ := &projCached{}
+	tmp1 := &projP1xP1{}
+	tmp2 := &projP2{}
+	tmp2.Zero()
+
+	for ; i >= 0; i-- {
+		tmp1.Double(tmp2)
+
+		if naf[i] > 0 {
+			v.fromP1xP1(tmp1)
+			table.SelectInto(multQ, naf[i])
+			tmp1.Add(v, multQ)
+		} else if naf[i] < 0 {
+			v.fromP1xP1(tmp1)
+			table.SelectInto(multQ, -naf[i])
+			tmp1.Sub(v, multQ)
+		}
+
+		tmp2.FromP1xP1(tmp1)
+	}
+
+	v.fromP2(tmp2)
+	return v
+}
+
+// BitLen returns the length of the canonical value of s in bits, that is, the
+// position of its highest set bit plus one. The result is 0 for s = 0.
+//
+// Execution time depends on the value of s, so BitLen must not be used on
+// secret values.
+func (s *Scalar) BitLen() int {
+	for i := len(s.s) - 1; i >= 0; i-- {
+		if s.s[i] != 0 {
+			return i*8 + bits.Len8(s.s[i])
+		}
+	}
+	return 0
+}
+
+// NAFWeight returns the number of nonzero digits of naf, such as one returned
+// by NonAdjacentFormChecked.
+//
+// Execution time depends on the digits, so NAFWeight must not be used on the
+// representation of secret values.
+func NAFWeight(naf [256]int8) int {
+	n := 0
+	for _, d := range naf {
+		if d != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// maxSmallScalarLog is the largest bound accepted by SmallScalarLog. It limits
+// the baby-step table to 2^16 entries.
+const maxSmallScalarLog = 1 << 32
+
+// SmallScalarLog returns k such that k * B = p, where B is the canonical
+// generator and 0 <= k < bound, and true. If there is no such k, or if bound is
+// larger than 2^32, it returns 0 and false.
+//
+// SmallScalarLog uses the baby-step giant-step algorithm, which takes time and
+// memory proportional to the square root of bound. It is meant for testing
+// scalar arithmetic on small values, and its execution time depends on the
+// inputs.
+func SmallScalarLog(p *Point, bound uint64) (uint64, bool) {
+	checkInitialized(p)
+	if bound == 0 || bound > maxSmallScalarLog {
+		return 0, false
+	}
+
+	m := uint64(1)
+	for m*m < bound {
+		m++
+	}
+
+	// Baby steps: record j * B for 0 <= j < m.
+	baby := make(map[[32]byte]uint64, m)
+	q := NewIdentityPoint()
+	for j := uint64(0); j < m; j++ {
+		var key [32]byte
+		q.bytes(&key)
+		baby[key] = j
+		q.Add(q, generator)
+	}
+
+	// Giant steps: look for p - i * m * B in the table, for 0 <= i < m.
+	var mScalar Scalar
+	binary.LittleEndian.PutUint64(mScalar.s[:8], m)
+	giant := new(Point).ScalarBaseMult(&mScalar)
+	gamma := new(Point).Set(p)
+	for i := uint64(0); i < m; i++ {
+		var key [32]byte
+		gamma.bytes(&key)
+		if j, ok := baby[key]; ok {
+			if k := i*m + j; k < bound {
+				return k, true
+			}
+			return 0, false
+		}
+		gamma.Subtract(gamma, giant)
+	}
+	return 0, false
+}
+
+// PointFromSeed returns a point in the prime-order subgroup derived
+// deterministically from seed, for use in tests and as fuzzing seeds. The seed
+// is hashed with SHA-512 to a field element, which is mapped to the curve with
+// the Elligator 2 map of RFC 9380, Section 6.7.1, and then multiplied by the
+// cofactor.
+//
+// The result is not uniformly distributed, so PointFromSeed must not be used
+// to hash to the curve or to generate keys.
+func PointFromSeed(seed uint64) *Point {
+	h := sha512.New()
+	h.Write([]byte("edwards25519 PointFromSeed"))
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], seed)
+	h.Write(b[:])
+	u, _ := new(field.Element).SetWideBytes(h.Sum(nil))
+	p := elligator2(u)
+	return p.MultByCofactor(p)
+}
+
+// sqrtMinusA2 is sqrt(-486664), with an even canonical encoding, used by the
+// birational map from Curve25519 to edwards25519.
+var sqrtMinusA2, _ = new(field.Element).SetBytes([]byte{
+	0x06, 0x7e, 0x45, 0xff, 0xaa, 0x04, 0x6e, 0xcc,
+	0x82, 0x1a, 0x7d, 0x4b, 0xd1, 0xd3, 0xa1, 0xc5,
+	0x7e, 0x4f, 0xfc, 0x03, 0xdc, 0x08, 0x7b, 0xd2,
+	0xbb, 0x06, 0xa0, 0x60, 0xf4, 0xed, 0x26, 0x0f})
+
+// elligator2 maps u to a point on edwards25519 with map_to_curve_elligator2
+// for Curve25519 with Z = 2, as specified in RFC 9380, Section 6.7.1, followed
+// by the rational map of Section 6.8.2. The result is not cleared of its
+// small-order component.
+func elligator2(u *field.Element) *Point {
+	var A, negA, x1, x2, gx1, gx2, tmp field.Element
+	A.Mult32(feOne, 486662)
+	negA.Negate(&A)
+
+	// x1 = -A / (1 + 2u²), or -A if the denominator is zero.
+	tmp.Square(u)
+	tmp.Add(&tmp, &tmp)
+	tmp.Add(&tmp, feOne)
+	x1.Multiply(&negA, tmp.Invert(&tmp))
+	x1.Select(&negA, &x1, x1.Equal(new(field.Element)))
+	x2.Subtract(&negA, &x1) // x2 = -x1 - A
+
+	// gx = x³ + Ax² + x = x * (x * (x + A) + 1)
+	curve := func(gx, x *field.Element) {
+		gx.Add(x, &A)
+		gx.Multiply(gx, x)
+		gx.Add(gx, feOne)
+		gx.Multiply(gx, x)
+	}
+	curve(&gx1, &x1)
+	curve(&gx2, &x2)
+
+	// If gx1 is square, (s, t) = (x1, -|sqrt(gx1)|), otherwise
+	// (s, t) = (x2, |sqrt(gx2)|).
+	var s, t, y1, y2 field.Element
+	_, isSquare := y1.Sqrt(&gx1)
+	y1.Negate(&y1)
+	y2.Sqrt(&gx2)
+	s.Select(&x1, &x2, isSquare)
+	t.Select(&y1, &y2, isSquare)
+
+	// (x, y) = (sqrt(-486664) * s / t, (s - 1) / (s + 1)), or the identity if
+	// either denominator is zero.
+	var sMinusOne, sPlusOne, x, y field.Element
+	sMinusOne.Subtract(&s, feOne)
+	sPlusOne.Add(&s, feOne)
+	x.Multiply(sqrtMinusA2, &s)
+	x.Multiply(&x, tmp.Invert(&t))
+	y.Multiply(&sMinusOne, tmp.Invert(&sPlusOne))
+	zero := new(field.Element)
+	exceptional := t.Equal(zero) | sPlusOne.Equal(zero)
+	x.Select(zero, &x, exceptional)
+	y.Select(feOne, &y, exceptional)
+
+	p := new(Point)
+	p.x.Set(&x)
+	p.y.Set(&y)
+	p.z.One()
+	p.t.Multiply(&x, &y)
+	return p
+}
@@ -5,9 +5,23 @@
 package edwards25519
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
 	"testing"
 	"testing/quick"
+
+	"filippo.io/edwards25519/field"
 )
 
 // TestBytesMontgomery tests the SetBytesWithClamping+BytesMontgomery path
@@ -165,3 +179,2321 @@ func BenchmarkMultiScalarMultSize8(t *testing.B) {
 			[]*Point{B, B, B, B, B, B, B, B})
 	}
 }
+
+func BenchmarkVarTimeMultiScalarMultSize8(t *testing.B) {
+	var p Point
+	x := dalekScalar
+
+	for i := 0; i < t.N; i++ {
+		p.VarTimeMultiScalarMult([]*Scalar{&x, &x, &x, &x, &x, &x, &x, &x},
+			[]*Point{B, B, B, B, B, B, B, B})
+	}
+}
+
+func TestAffineBytes(t *testing.T) {
+	f := func(scalar [64]byte) bool {
+		s, _ := NewScalar().SetUniformBytes(scalar[:])
+		p := (&Point{}).ScalarBaseMult(s)
+		x, y := p.AffineBytes()
+
+		// The compressed encoding is y with the sign of x in the top bit.
+		enc := p.Bytes()
+		if !bytes.Equal(enc[:31], y[:31]) || enc[31]&0x7f != y[31] ||
+			enc[31]>>7 != x[0]&1 {
+			return false
+		}
+
+		q, err := (&Point{}).SetAffineBytes(x, y)
+		if err != nil {
+			return false
+		}
+		checkOnCurve(t, q)
+		return q.Equal(p) == 1
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	x, y := B.AffineBytes()
+	if got := hex.EncodeToString(x); got != hex.EncodeToString(B.x.Bytes()) {
+		t.Errorf("wrong B.x: got %s", got)
+	}
+	if got := hex.EncodeToString(y); got != hex.EncodeToString(B.y.Bytes()) {
+		t.Errorf("wrong B.y: got %s", got)
+	}
+}
+
+func TestSetAffineBytesInvalid(t *testing.T) {
+	x, y := B.AffineBytes()
+
+	// Perturbing y moves the point off the curve.
+	yy := append([]byte{}, y...)
+	yy[0] ^= 1
+	p := NewGeneratorPoint()
+	if out, err := p.SetAffineBytes(x, yy); err == nil || out != nil {
+		t.Error("expected error for off-curve coordinates")
+	} else if p.Equal(B) != 1 {
+		t.Error("the Point was modified while decoding invalid coordinates")
+	}
+
+	// y = p + 1 is a non-canonical encoding of the valid y of the identity.
+	yNonCanonical := decodeHex("eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	if _, err := p.SetAffineBytes(make([]byte, 32), yNonCanonical); err == nil {
+		t.Error("expected error for non-canonical y")
+	}
+	one := decodeHex("0100000000000000000000000000000000000000000000000000000000000000")
+	if _, err := p.SetAffineBytes(make([]byte, 32), one); err != nil {
+		t.Errorf("unexpected error decoding the identity: %v", err)
+	} else if p.Equal(I) != 1 {
+		t.Error("(0, 1) did not decode to the identity")
+	}
+
+	if _, err := p.SetAffineBytes(x[:31], y); err == nil {
+		t.Error("expected error for short x")
+	}
+}
+
+func TestScalarString(t *testing.T) {
+	f := func(s Scalar) bool {
+		return s.String() == hex.EncodeToString(s.Bytes()) &&
+			fmt.Sprint(&s) == s.String()
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	want := "ecd3f55c1a631258d69cf7a2def9de1400000000000000000000000000000010"
+	if got := scMinusOne.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPointString(t *testing.T) {
+	want := "Point(5866666666666666666666666666666666666666666666666666666666666666)"
+	if got := B.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := fmt.Sprint(B); got != want {
+		t.Errorf("fmt.Sprint: got %q, want %q", got, want)
+	}
+	want = "Point(0100000000000000000000000000000000000000000000000000000000000000)"
+	if got := I.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestScalarDotProduct(t *testing.T) {
+	f := func(a, b [5]Scalar) bool {
+		var as, bs []*Scalar
+		var check, tmp Scalar
+		for i := range a {
+			as = append(as, &a[i])
+			bs = append(bs, &b[i])
+			check.Add(&check, tmp.Multiply(&a[i], &b[i]))
+		}
+
+		var out Scalar
+		if err := ScalarDotProduct(&out, as, bs); err != nil {
+			return false
+		}
+		if out != check {
+			return false
+		}
+
+		// Aliasing the output with an input.
+		if err := ScalarDotProduct(as[0], as, bs); err != nil {
+			return false
+		}
+		return *as[0] == check
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	out := NewScalar().Set(&scOne)
+	if err := ScalarDotProduct(out, nil, nil); err != nil || out.Equal(NewScalar()) != 1 {
+		t.Error("empty dot product is not zero")
+	}
+	if err := ScalarDotProduct(out, []*Scalar{&scOne}, nil); err == nil {
+		t.Error("expected error for different size inputs")
+	}
+}
+
+func BenchmarkScalarDotProduct256(b *testing.B) {
+	as := make([]*Scalar, 256)
+	for i := range as {
+		as[i] = &dalekScalar
+	}
+	var out Scalar
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ScalarDotProduct(&out, as, as)
+	}
+}
+
+// stubHash is a hash.Hash that ignores its input and returns a fixed sum.
+type stubHash struct{ sum []byte }
+
+func (h stubHash) Write(p []byte) (int, error) { return len(p), nil }
+func (h stubHash) Sum(b []byte) []byte         { return append(b, h.sum...) }
+func (h stubHash) Reset()                      {}
+func (h stubHash) Size() int                   { return len(h.sum) }
+func (h stubHash) BlockSize() int              { return 128 }
+
+func TestScalarSetFromHash(t *testing.T) {
+	f := func(in [64]byte) bool {
+		s, err := NewScalar().SetFromHash(stubHash{in[:]})
+		if err != nil {
+			return false
+		}
+		check, _ := NewScalar().SetUniformBytes(in[:])
+		return s.Equal(check) == 1
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	// Only the first 64 bytes of longer outputs are used.
+	long := make([]byte, 80)
+	long[0], long[70] = 42, 1
+	s, err := NewScalar().SetFromHash(stubHash{long})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Equal(&Scalar{[32]byte{42}}) != 1 {
+		t.Errorf("got %v, want 42", s)
+	}
+
+	s = NewScalar().Set(&scOne)
+	if out, err := s.SetFromHash(sha256.New()); err == nil || out != nil {
+		t.Error("expected error for short hash output")
+	} else if s.Equal(&scOne) != 1 {
+		t.Error("SetFromHash modified its receiver")
+	}
+
+	// SHA-512 works out of the box.
+	h := sha512.New()
+	h.Write([]byte("transcript"))
+	check, _ := NewScalar().SetUniformBytes(h.Sum(nil))
+	if s, err := NewScalar().SetFromHash(h); err != nil || s.Equal(check) != 1 {
+		t.Error("SetFromHash does not match SetUniformBytes for SHA-512")
+	}
+}
+
+func TestOrder(t *testing.T) {
+	l := bigIntFromLittleEndianBytes(scMinusOne.s[:])
+	l.Add(l, big.NewInt(1))
+	if Order().Cmp(l) != 0 {
+		t.Errorf("got %v, want %v", Order(), l)
+	}
+
+	// Modifying the returned value doesn't affect later calls.
+	Order().SetInt64(0)
+	if Order().Cmp(l) != 0 {
+		t.Error("Order returned a shared value")
+	}
+}
+
+func TestCurveParameters(t *testing.T) {
+	if Cofactor() != 8 {
+		t.Errorf("got cofactor %d, want 8", Cofactor())
+	}
+	if p := (&Point{}).ScalarMult(&Scalar{[32]byte{byte(Cofactor())}}, B); p.Equal(
+		(&Point{}).MultByCofactor(B)) != 1 {
+		t.Error("Cofactor does not match MultByCofactor")
+	}
+
+	want, _ := new(big.Int).SetString("37095705934669439343138083508754565189542113879843219016388785533085940283555", 10)
+	if got := bigIntFromLittleEndianBytes(CurveD()); got.Cmp(want) != 0 {
+		t.Errorf("got d = %v, want %v", got, want)
+	}
+
+	// d = -121665/121666 mod p
+	p := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	check := new(big.Int).ModInverse(big.NewInt(121666), p)
+	check.Mul(check, big.NewInt(-121665)).Mod(check, p)
+	if want.Cmp(check) != 0 {
+		t.Errorf("d is not -121665/121666")
+	}
+
+	// Modifying the returned value doesn't affect d.
+	CurveD()[0] ^= 0xff
+	if got := bigIntFromLittleEndianBytes(CurveD()); got.Cmp(want) != 0 {
+		t.Error("CurveD returned a shared value")
+	}
+
+	if !bytes.Equal(CurveDField().Bytes(), CurveD()) {
+		t.Error("CurveDField does not match CurveD")
+	}
+	twoD := new(field.Element).Add(CurveDField(), CurveDField())
+	if CurveTwoDField().Equal(twoD) != 1 {
+		t.Error("CurveTwoDField is not d + d")
+	}
+	CurveDField().Zero()
+	CurveTwoDField().Zero()
+	if !bytes.Equal(CurveDField().Bytes(), CurveD()) || CurveTwoDField().Equal(twoD) != 1 {
+		t.Error("CurveDField or CurveTwoDField returned a shared value")
+	}
+}
+
+func TestScalarCondNeg(t *testing.T) {
+	f := func(x Scalar) bool {
+		var neg, s Scalar
+		neg.Negate(&x)
+
+		if s.CondNeg(&x, 0); s != x {
+			return false
+		}
+		if s.CondNeg(&x, 1); s != neg {
+			return false
+		}
+
+		// Aliasing the argument and the receiver.
+		s = x
+		if out := s.CondNeg(&s, 1); out != &s || s != neg {
+			return false
+		}
+		s = x
+		if out := s.CondNeg(&s, 0); out != &s || s != x {
+			return false
+		}
+		return isReduced(&s)
+	}
+	if err := quick.Check(f, quickCheckConfig1024); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSetUniformBytesBatch(t *testing.T) {
+	f := func(in [5 * 64]byte) bool {
+		dst := make([]*Scalar, 5)
+		for i := range dst {
+			dst[i] = NewScalar()
+		}
+		if err := SetUniformBytesBatch(dst, in[:]); err != nil {
+			return false
+		}
+		for i := range dst {
+			check, _ := NewScalar().SetUniformBytes(in[64*i : 64*(i+1)])
+			if dst[i].Equal(check) != 1 {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	dst := []*Scalar{NewScalar().Set(&scOne), NewScalar().Set(&scOne)}
+	for _, n := range []int{0, 64, 127, 129, 192} {
+		if err := SetUniformBytesBatch(dst, make([]byte, n)); err == nil {
+			t.Errorf("expected error for input length %d", n)
+		}
+	}
+	if dst[0].Equal(&scOne) != 1 || dst[1].Equal(&scOne) != 1 {
+		t.Error("SetUniformBytesBatch modified dst on error")
+	}
+	if err := SetUniformBytesBatch(nil, nil); err != nil {
+		t.Errorf("unexpected error for empty input: %v", err)
+	}
+}
+
+func TestSetBytesWithSign(t *testing.T) {
+	f := func(scalar [64]byte) bool {
+		s, _ := NewScalar().SetUniformBytes(scalar[:])
+		enc := (&Point{}).ScalarBaseMult(s).Bytes()
+
+		p := &Point{}
+		negativeX, err := p.SetBytesWithSign(enc)
+		if err != nil || negativeX != int(enc[31]>>7) {
+			return false
+		}
+		return bytes.Equal(p.Bytes(), enc)
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	// x = 0 with the sign bit set decodes to a non-negative x.
+	p := NewGeneratorPoint()
+	enc := decodeHex("0100000000000000000000000000000000000000000000000000000000000080")
+	if negativeX, err := p.SetBytesWithSign(enc); err != nil || negativeX != 0 {
+		t.Errorf("got %d, %v; want 0, nil", negativeX, err)
+	}
+
+	p = NewGeneratorPoint()
+	invalid := decodeHex("efffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	if _, err := p.SetBytesWithSign(invalid); err == nil {
+		t.Error("expected error for invalid point")
+	} else if p.Equal(B) != 1 {
+		t.Error("the Point was modified while decoding an invalid encoding")
+	}
+}
+
+func TestSmallScalarLog(t *testing.T) {
+	for _, k := range []uint64{0, 1, 2, 42, 999, 1000, 65535, 1<<20 - 1} {
+		var s Scalar
+		binary.LittleEndian.PutUint64(s.s[:8], k)
+		p := (&Point{}).ScalarBaseMult(&s)
+		if got, ok := SmallScalarLog(p, 1<<20); !ok || got != k {
+			t.Errorf("SmallScalarLog(%d * B) = %d, %v", k, got, ok)
+		}
+	}
+
+	// Out of range values are not found.
+	p := (&Point{}).ScalarBaseMult(&Scalar{[32]byte{100}})
+	if _, ok := SmallScalarLog(p, 100); ok {
+		t.Error("found 100 in [0, 100)")
+	}
+	if got, ok := SmallScalarLog(p, 101); !ok || got != 100 {
+		t.Errorf("SmallScalarLog(100 * B) = %d, %v", got, ok)
+	}
+	if _, ok := SmallScalarLog(I, 0); ok {
+		t.Error("found a value in the empty range")
+	}
+	if _, ok := SmallScalarLog(I, 1<<32+1); ok {
+		t.Error("accepted a bound above 2^32")
+	}
+	if _, ok := SmallScalarLog((&Point{}).ScalarBaseMult(&dalekScalar), 1<<16); ok {
+		t.Error("found the log of a large scalar")
+	}
+}
+
+func TestScalarIsInvertible(t *testing.T) {
+	if NewScalar().IsInvertible() != 0 {
+		t.Error("zero is invertible")
+	}
+	f := func(x notZeroScalar) bool {
+		return (*Scalar)(&x).IsInvertible() == 1
+	}
+	if err := quick.Check(f, quickCheckConfig1024); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScalarFillBytes(t *testing.T) {
+	f := func(s Scalar) bool {
+		buf := make([]byte, 40)
+		out, err := s.FillBytes(buf)
+		return err == nil && len(out) == 32 && &out[0] == &buf[0] &&
+			bytes.Equal(out, s.Bytes())
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	if out, err := scOne.FillBytes(make([]byte, 31)); err == nil || out != nil {
+		t.Error("expected error for short buffer")
+	}
+
+	var buf [32]byte
+	if allocs := testing.AllocsPerRun(100, func() {
+		scMinusOne.FillBytes(buf[:])
+	}); allocs > 0 {
+		t.Errorf("expected zero allocations, got %0.1v", allocs)
+	}
+}
+
+func TestPointFillBytes(t *testing.T) {
+	f := func(scalar [64]byte) bool {
+		s, _ := NewScalar().SetUniformBytes(scalar[:])
+		p := (&Point{}).ScalarBaseMult(s)
+		buf := make([]byte, 64)
+		out, err := p.FillBytes(buf[32:])
+		return err == nil && len(out) == 32 && &out[0] == &buf[32] &&
+			bytes.Equal(out, p.Bytes())
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	if out, err := B.FillBytes(make([]byte, 31)); err == nil || out != nil {
+		t.Error("expected error for short buffer")
+	}
+
+	if strings.HasSuffix(os.Getenv("GO_BUILDER_NAME"), "-noopt") {
+		t.Skip("skipping allocations test without relevant optimizations")
+	}
+	var buf [32]byte
+	if allocs := testing.AllocsPerRun(100, func() {
+		B.FillBytes(buf[:])
+	}); allocs > 0 {
+		t.Errorf("expected zero allocations, got %0.1v", allocs)
+	}
+}
+
+func TestScalarIsLowHalf(t *testing.T) {
+	half := new(big.Int).Rsh(Order(), 1)
+	if bigIntFromLittleEndianBytes(scHalfOrder.Bytes()).Cmp(half) != 0 {
+		t.Fatal("scHalfOrder is not (l - 1) / 2")
+	}
+	halfPlusOne := new(Scalar).Add(&scHalfOrder, &scOne)
+
+	for _, tt := range []struct {
+		name string
+		s    *Scalar
+		want int
+	}{
+		{"zero", NewScalar(), 1},
+		{"one", &scOne, 1},
+		{"(l - 1) / 2", &scHalfOrder, 1},
+		{"(l + 1) / 2", halfPlusOne, 0},
+		{"l - 1", &scMinusOne, 0},
+	} {
+		if got := tt.s.IsLowHalf(); got != tt.want {
+			t.Errorf("IsLowHalf(%s) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	// (l + 1) / 2 = -((l - 1) / 2).
+	if new(Scalar).Set(halfPlusOne).CanonicalizeLowHalf().Equal(&scHalfOrder) != 1 {
+		t.Error("CanonicalizeLowHalf((l + 1) / 2) != (l - 1) / 2")
+	}
+
+	f := func(x Scalar) bool {
+		xBig := bigIntFromLittleEndianBytes(x.Bytes())
+		want := 0
+		if xBig.Cmp(half) <= 0 {
+			want = 1
+		}
+		if x.IsLowHalf() != want {
+			return false
+		}
+		neg := new(Scalar).Negate(&x)
+		if x.Equal(NewScalar()) == 0 && x.IsLowHalf() == neg.IsLowHalf() {
+			return false
+		}
+
+		c := new(Scalar).Set(&x)
+		if c.CanonicalizeLowHalf() != c || c.IsLowHalf() != 1 {
+			return false
+		}
+		return c.Equal(&x) == 1 || c.Equal(neg) == 1
+	}
+	if err := quick.Check(f, quickCheckConfig1024); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScalarCmp(t *testing.T) {
+	f := func(x, y Scalar) bool {
+		xBig := bigIntFromLittleEndianBytes(x.s[:])
+		yBig := bigIntFromLittleEndianBytes(y.s[:])
+		return x.Cmp(&y) == xBig.Cmp(yBig) && x.Cmp(&x) == 0
+	}
+	if err := quick.Check(f, quickCheckConfig1024); err != nil {
+		t.Error(err)
+	}
+
+	two := &Scalar{[32]byte{2}}
+	high := &Scalar{[32]byte{31: 1}}
+	for _, tt := range []struct {
+		x, y *Scalar
+		want int
+	}{
+		{&scZero, &scOne, -1},
+		{&scOne, &scZero, 1},
+		{&scOne, &scOne, 0},
+		{two, &scOne, 1},
+		{two, high, -1},
+		{&scMinusOne, high, 1},
+		{&scMinusOne, &scMinusOne, 0},
+		{&scZero, &scMinusOne, -1},
+	} {
+		if got := tt.x.Cmp(tt.y); got != tt.want {
+			t.Errorf("%v.Cmp(%v) = %d, want %d", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestHashPointToScalar(t *testing.T) {
+	// Generated with Python's hashlib.sha512 and integer arithmetic.
+	want := "1841b987081161eef73ab7a425478a038c6095738f1b02d44f2114bb1c27c90a"
+	s := HashPointToScalar(B, []byte("HashPointToScalar-test"))
+	if got := hex.EncodeToString(s.Bytes()); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// The encoding of the point doesn't depend on its representation.
+	p := (&Point{}).ScalarMult(&scOne, B)
+	if HashPointToScalar(p, []byte("HashPointToScalar-test")).Equal(s) != 1 {
+		t.Error("different representations of B hashed differently")
+	}
+	if HashPointToScalar(B, []byte("HashPointToScalar-test2")).Equal(s) == 1 {
+		t.Error("different DSTs hashed to the same scalar")
+	}
+}
+
+func TestScalarSetBytesModOrder(t *testing.T) {
+	l := Order()
+	f := func(in [32]byte) bool {
+		s, err := NewScalar().SetBytesModOrder(in[:])
+		if err != nil || !isReduced(s) {
+			return false
+		}
+		inBig := bigIntFromLittleEndianBytes(in[:])
+		return inBig.Mod(inBig, l).Cmp(bigIntFromLittleEndianBytes(s.s[:])) == 0
+	}
+	if err := quick.Check(f, quickCheckConfig1024); err != nil {
+		t.Error(err)
+	}
+
+	// l encoded as 32 bytes reduces to zero, while SetCanonicalBytes rejects it.
+	lBytes := scMinusOne.s
+	lBytes[0]++
+	if s, err := NewScalar().SetBytesModOrder(lBytes[:]); err != nil || s.Equal(&scZero) != 1 {
+		t.Errorf("l did not reduce to zero: %v, %v", s, err)
+	}
+	if _, err := NewScalar().SetCanonicalBytes(lBytes[:]); err == nil {
+		t.Error("SetCanonicalBytes accepted l")
+	}
+
+	s := NewScalar().Set(&scOne)
+	if out, err := s.SetBytesModOrder(make([]byte, 33)); err == nil || out != nil {
+		t.Error("expected error for wrong length")
+	} else if s.Equal(&scOne) != 1 {
+		t.Error("SetBytesModOrder modified its receiver")
+	}
+}
+
+func TestReadWriteScalars(t *testing.T) {
+	f := func(in [4]Scalar) bool {
+		ss := []*Scalar{&in[0], &in[1], &in[2], &in[3]}
+		var buf bytes.Buffer
+		if err := WriteScalars(&buf, ss); err != nil || buf.Len() != 4*32 {
+			return false
+		}
+		out, err := ReadScalars(&buf, len(ss))
+		if err != nil || len(out) != len(ss) {
+			return false
+		}
+		for i := range ss {
+			if out[i].Equal(ss[i]) != 1 {
+				return false
+			}
+		}
+		return buf.Len() == 0
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	var buf bytes.Buffer
+	WriteScalars(&buf, []*Scalar{&scOne, &scMinusOne})
+	if _, err := ReadScalars(bytes.NewReader(buf.Bytes()[:40]), 2); err == nil {
+		t.Error("expected error for truncated input")
+	} else if !errors.Is(err, io.ErrUnexpectedEOF) || !strings.Contains(err.Error(), "scalar 1") {
+		t.Errorf("unexpected error for truncated input: %v", err)
+	}
+
+	nonCanonical := append(buf.Bytes(), bytes.Repeat([]byte{0xff}, 32)...)
+	if _, err := ReadScalars(bytes.NewReader(nonCanonical), 3); err == nil {
+		t.Error("expected error for non-canonical input")
+	} else if !strings.Contains(err.Error(), "scalar 2") {
+		t.Errorf("unexpected error for non-canonical input: %v", err)
+	}
+
+	if ss, err := ReadScalars(bytes.NewReader(nil), 0); err != nil || len(ss) != 0 {
+		t.Errorf("unexpected result for empty input: %v, %v", ss, err)
+	}
+	if _, err := ReadScalars(bytes.NewReader(nil), -1); err == nil {
+		t.Error("expected error for negative count")
+	}
+}
+
+func TestAffineBytesOnCurve(t *testing.T) {
+	lowOrder, err := (&Point{}).SetBytes(decodeHex("26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc85"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []*Point{I, B, lowOrder, (&Point{}).ScalarMult(&dalekScalar, B)} {
+		x, y := p.AffineBytes()
+		X, err := new(field.Element).SetBytes(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		Y, err := new(field.Element).SetBytes(y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		T := new(field.Element).Multiply(X, Y)
+		if !isOnCurve(X, Y, feOne, T) {
+			t.Errorf("affine coordinates of %v are not on the curve", p)
+		}
+	}
+}
+
+func TestIsOnCurveBytes(t *testing.T) {
+	x, y := B.AffineBytes()
+	if ok, err := IsOnCurveBytes(x, y); err != nil || !ok {
+		t.Errorf("generator: got %v, %v", ok, err)
+	}
+
+	yy := append([]byte{}, y...)
+	yy[0]++
+	if ok, err := IsOnCurveBytes(x, yy); err != nil || ok {
+		t.Errorf("perturbed y: got %v, %v", ok, err)
+	}
+
+	// p + 1 is a non-canonical encoding of 1.
+	pPlusOne := decodeHex("eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	if _, err := IsOnCurveBytes(make([]byte, 32), pPlusOne); err == nil {
+		t.Error("expected error for non-canonical y")
+	}
+	highBit := append([]byte{}, x...)
+	highBit[31] |= 0x80
+	if _, err := IsOnCurveBytes(highBit, y); err == nil {
+		t.Error("expected error for x with the high bit set")
+	}
+	if _, err := IsOnCurveBytes(append(x, 0), y); err == nil {
+		t.Error("expected error for overlong x")
+	}
+}
+
+func TestScalarZero(t *testing.T) {
+	f := func(s Scalar) bool {
+		if out := s.Zero(); out != &s {
+			return false
+		}
+		return bytes.Equal(s.Bytes(), make([]byte, 32)) &&
+			s.Equal(NewScalar()) == 1 && s.IsInvertible() == 0
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScalarOne(t *testing.T) {
+	if One().Equal(&scOne) != 1 {
+		t.Errorf("One() = %v", One())
+	}
+	f := func(x Scalar) bool {
+		var s Scalar
+		return s.Multiply(&x, One()) == &s && s == x
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	// One returns a new value every time.
+	One().Zero()
+	if One().Equal(&scOne) != 1 || scOne.Equal(NewScalar()) == 1 {
+		t.Error("One returned a shared value")
+	}
+}
+
+func TestScalarNegBytes(t *testing.T) {
+	f := func(s Scalar) bool {
+		orig := s
+		return bytes.Equal(s.NegBytes(), new(Scalar).Negate(&s).Bytes()) && s == orig
+	}
+	if err := quick.Check(f, quickCheckConfig1024); err != nil {
+		t.Error(err)
+	}
+
+	if !bytes.Equal(NewScalar().NegBytes(), make([]byte, 32)) {
+		t.Error("NegBytes of zero is not zero")
+	}
+	if !bytes.Equal(One().NegBytes(), scMinusOne.Bytes()) {
+		t.Error("NegBytes of one is not l - 1")
+	}
+}
+
+func TestDeriveScalar(t *testing.T) {
+	tests := []struct {
+		ikm, salt, info string
+		want            string
+	}{
+		{"input key material", "salt", "info",
+			"43f679d7e79ea8e87f97e1d7b7e8594824eb5b09861ca059c9a7208123781005"},
+		{"input key material", "", "",
+			"2149c08fccee390692b5182a6de2f9da294f2992258bf4b0b3594800541d860b"},
+	}
+	for _, tt := range tests {
+		s := DeriveScalar([]byte(tt.ikm), []byte(tt.salt), []byte(tt.info))
+		if got := hex.EncodeToString(s.Bytes()); got != tt.want {
+			t.Errorf("DeriveScalar(%q, %q, %q) = %s, want %s",
+				tt.ikm, tt.salt, tt.info, got, tt.want)
+		}
+	}
+
+	ikm := []byte("input key material")
+	a := DeriveScalar(ikm, nil, []byte("key 0"))
+	b := DeriveScalar(ikm, nil, []byte("key 1"))
+	if a.Equal(b) == 1 {
+		t.Error("different info strings produced the same scalar")
+	}
+	if a.Equal(DeriveScalar(ikm, nil, []byte("key 0"))) != 1 {
+		t.Error("DeriveScalar is not deterministic")
+	}
+}
+
+func TestScalarFromLabel(t *testing.T) {
+	tests := []struct {
+		label, want string
+	}{
+		{"alice-blinding", "9eaba09829e70c4bbac18eb8ad8b418af1e671583474e8f95688e627e2c5cc01"},
+		{"", "4819012d8d4a4e7209e39a4760c0740997aa0fbdcac359d4f071d969997f120f"},
+	}
+	for _, tt := range tests {
+		if got := hex.EncodeToString(ScalarFromLabel(tt.label).Bytes()); got != tt.want {
+			t.Errorf("ScalarFromLabel(%q) = %s, want %s", tt.label, got, tt.want)
+		}
+	}
+
+	if ScalarFromLabel("a").Equal(ScalarFromLabel("b")) == 1 {
+		t.Error("different labels produced the same scalar")
+	}
+}
+
+func TestVarTimeScalarMult(t *testing.T) {
+	f := func(x Scalar) bool {
+		var q, want, got Point
+		q.ScalarBaseMult(&x)
+		want.ScalarMult(&x, &q)
+		got.VarTimeScalarMult(&x, &q)
+		checkOnCurve(t, &got)
+		return got.Equal(&want) == 1
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	// Short scalars select narrower NAF widths.
+	for _, k := range []uint64{0, 1, 2, 3, 7, 255, 1 << 16, 1<<32 - 1, 1<<63 + 1} {
+		var x Scalar
+		binary.LittleEndian.PutUint64(x.s[:8], k)
+		var want, got Point
+		want.ScalarMult(&x, B)
+		got.VarTimeScalarMult(&x, B)
+		if got.Equal(&want) != 1 {
+			t.Errorf("VarTimeScalarMult(%d, B) does not match ScalarMult", k)
+		}
+	}
+
+	// Aliasing the receiver and the point is fine.
+	p := new(Point).Set(B)
+	p.VarTimeScalarMult(&dalekScalar, p)
+	if p.Equal(dalekScalarBasepoint) != 1 {
+		t.Error("VarTimeScalarMult does not match dalek when aliased")
+	}
+}
+
+func TestVarTimeScalarMultFastPaths(t *testing.T) {
+	f := func(x, y Scalar) bool {
+		q := new(Point).ScalarBaseMult(&y)
+
+		// The generator, in a different representation than B.
+		g := new(Point).Add(B, q)
+		g.Subtract(g, q)
+
+		for _, tt := range []struct {
+			x *Scalar
+			q *Point
+		}{
+			{NewScalar(), q},
+			{&x, NewIdentityPoint()},
+			{NewScalar(), NewIdentityPoint()},
+			{&x, B},
+			{&x, g},
+		} {
+			var got, want Point
+			want.ScalarMult(tt.x, tt.q)
+			got.VarTimeScalarMult(tt.x, tt.q)
+			checkOnCurve(t, &got)
+			if got.Equal(&want) != 1 {
+				return false
+			}
+		}
+
+		// The receiver may alias the point in the fast paths too.
+		aliased := new(Point).Set(B)
+		return aliased.VarTimeScalarMult(&x, aliased).Equal(new(Point).ScalarBaseMult(&x)) == 1
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func BenchmarkVarTimeScalarMult(b *testing.B) {
+	var p Point
+	for i := 0; i < b.N; i++ {
+		p.VarTimeScalarMult(&dalekScalar, B)
+	}
+}
+
+func TestSumPoints(t *testing.T) {
+	f := func(x, y, z Scalar) bool {
+		var p, q, r Point
+		p.ScalarBaseMult(&x)
+		q.ScalarBaseMult(&y)
+		r.ScalarBaseMult(&z)
+		want := new(Point).Add(&p, &q)
+		want.Add(want, &r)
+
+		got := SumPoints(new(Point), []*Point{&p, &q, &r})
+		if got.Equal(want) != 1 {
+			return false
+		}
+		// dst may alias an element of points.
+		return SumPoints(&q, []*Point{&p, &q, &r}).Equal(want) == 1
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	dst := new(Point).Set(B)
+	if SumPoints(dst, nil) != dst || dst.Equal(I) != 1 {
+		t.Error("SumPoints of an empty slice is not the identity")
+	}
+}
+
+func TestPointAccumulator(t *testing.T) {
+	f := func(xs [5]Scalar) bool {
+		var a PointAccumulator
+		var points []*Point
+		for i := range xs {
+			p := new(Point).ScalarBaseMult(&xs[i])
+			points = append(points, p)
+			a.Add(p)
+			if a.Sum().Equal(SumPoints(new(Point), points)) != 1 {
+				return false
+			}
+		}
+		// Sum does not reset the accumulator.
+		return a.Add(B).Sum().Equal(SumPoints(new(Point), append(points, B))) == 1
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	var a PointAccumulator
+	if a.Sum().Equal(I) != 1 {
+		t.Error("the sum of an empty accumulator is not the identity")
+	}
+	// Sum returns a copy.
+	a.Add(B).Sum().Add(B, B)
+	if a.Sum().Equal(B) != 1 {
+		t.Error("modifying the result of Sum modified the accumulator")
+	}
+}
+
+func BenchmarkPointAccumulator(b *testing.B) {
+	points := make([]*Point, 64)
+	for i := range points {
+		points[i] = new(Point).ScalarBaseMult(ScalarFromLabel(fmt.Sprint(i)))
+	}
+	b.Run("SumPoints", func(b *testing.B) {
+		b.ReportAllocs()
+		var dst Point
+		for i := 0; i < b.N; i++ {
+			SumPoints(&dst, points)
+		}
+	})
+	b.Run("PointAccumulator", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var a PointAccumulator
+			for _, p := range points {
+				a.Add(p)
+			}
+		}
+	})
+}
+
+func TestScalarProduct(t *testing.T) {
+	f := func(a [5]Scalar) bool {
+		var ss []*Scalar
+		check := big.NewInt(1)
+		for i := range a {
+			ss = append(ss, &a[i])
+			check.Mul(check, bigIntFromLittleEndianBytes(a[i].Bytes()))
+			check.Mod(check, Order())
+		}
+
+		var out Scalar
+		if ScalarProduct(&out, ss) != &out {
+			return false
+		}
+		if bigIntFromLittleEndianBytes(out.Bytes()).Cmp(check) != 0 {
+			return false
+		}
+
+		// Aliasing the output with an input.
+		return ScalarProduct(ss[2], ss).Equal(&out) == 1
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	if ScalarProduct(NewScalar(), nil).Equal(One()) != 1 {
+		t.Error("empty product is not one")
+	}
+	withZero := []*Scalar{&dalekScalar, NewScalar(), &dalekScalar}
+	if ScalarProduct(One(), withZero).Equal(NewScalar()) != 1 {
+		t.Error("product including zero is not zero")
+	}
+}
+
+func TestLagrangeCoefficients(t *testing.T) {
+	// Shares of f(x) = secret + a * x + b * x^2 at five points, any three of
+	// which are enough to recover the secret.
+	f := func(secret, a, b Scalar, x [5]Scalar) bool {
+		var xs, ys []*Scalar
+		for i := range x {
+			var y Scalar
+			y.MultiplyAdd(&b, &x[i], &a)
+			y.MultiplyAdd(&y, &x[i], &secret)
+			xs = append(xs, &x[i])
+			ys = append(ys, &y)
+		}
+
+		for _, k := range []int{3, 4, 5} {
+			coeffs, err := LagrangeCoefficients(xs[:k])
+			if hasDuplicateScalars(xs[:k]) {
+				if err == nil {
+					return false
+				}
+				continue
+			}
+			if err != nil {
+				return false
+			}
+			var got Scalar
+			if err := ScalarDotProduct(&got, coeffs, ys[:k]); err != nil {
+				return false
+			}
+			if got.Equal(&secret) != 1 {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	// The usual share indexes 1, 2, 3 have coefficients 3, -3, 1.
+	var one, two, three Scalar
+	one.Set(&scOne)
+	two.Add(&one, &one)
+	three.Add(&two, &one)
+	coeffs, err := LagrangeCoefficients([]*Scalar{&one, &two, &three})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Scalar{&three, new(Scalar).Negate(&three), &one}
+	for i := range want {
+		if coeffs[i].Equal(want[i]) != 1 {
+			t.Errorf("coefficient %d = %v, want %v", i, coeffs[i], want[i])
+		}
+	}
+
+	if coeffs, err := LagrangeCoefficients(nil); err != nil || len(coeffs) != 0 {
+		t.Errorf("empty input: got %v, %v", coeffs, err)
+	}
+	if _, err := LagrangeCoefficients([]*Scalar{&one, &two, &one}); err == nil {
+		t.Error("expected error for duplicate input")
+	}
+}
+
+func hasDuplicateScalars(ss []*Scalar) bool {
+	for i := range ss {
+		for j := 0; j < i; j++ {
+			if ss[i].Equal(ss[j]) == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestPointSelect(t *testing.T) {
+	f := func(x, y Scalar) bool {
+		var a, b, v Point
+		a.ScalarBaseMult(&x)
+		b.ScalarBaseMult(&y)
+		if v.Select(&a, &b, 1) != &v || v.Equal(&a) != 1 {
+			return false
+		}
+		if v.Select(&a, &b, 0).Equal(&b) != 1 {
+			return false
+		}
+
+		// The receiver may alias either input.
+		aa, bb := a, b
+		if aa.Select(&aa, &b, 0).Equal(&b) != 1 {
+			return false
+		}
+		if bb.Select(&a, &bb, 1).Equal(&a) != 1 {
+			return false
+		}
+		aa = a
+		return aa.Select(&aa, &b, 1).Equal(&a) == 1
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPointNegBytes(t *testing.T) {
+	f := func(x Scalar) bool {
+		var p Point
+		p.ScalarBaseMult(&x)
+		orig := p
+		want := new(Point).Negate(&p).Bytes()
+		return bytes.Equal(p.NegBytes(), want) && p.Equal(&orig) == 1
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	// Points with x = 0 are their own negation, and keep a zero sign bit.
+	for _, enc := range []string{
+		"0100000000000000000000000000000000000000000000000000000000000000",
+		"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	} {
+		p, err := new(Point).SetBytes(decodeHex(enc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(p.NegBytes()); got != enc {
+			t.Errorf("NegBytes of %s = %s", enc, got)
+		}
+	}
+}
+
+func TestScalarBigEndian(t *testing.T) {
+	f := func(s Scalar) bool {
+		le, be := s.Bytes(), s.BytesBE()
+		for i := range le {
+			if le[i] != be[31-i] {
+				return false
+			}
+		}
+		var out Scalar
+		if r, err := out.SetCanonicalBytesBE(be); err != nil || r != &out {
+			return false
+		}
+		return out == s
+	}
+	if err := quick.Check(f, quickCheckConfig1024); err != nil {
+		t.Error(err)
+	}
+
+	// l is the smallest non-canonical value.
+	lBE := Order().FillBytes(make([]byte, 32))
+	s := One()
+	if out, err := s.SetCanonicalBytesBE(lBE); err == nil || out != nil {
+		t.Error("SetCanonicalBytesBE accepted l")
+	}
+	if s.Equal(One()) != 1 {
+		t.Error("SetCanonicalBytesBE modified the receiver on error")
+	}
+	lMinusOneBE := new(big.Int).Sub(Order(), big.NewInt(1)).FillBytes(make([]byte, 32))
+	if out, err := s.SetCanonicalBytesBE(lMinusOneBE); err != nil || out.Equal(&scMinusOne) != 1 {
+		t.Errorf("SetCanonicalBytesBE(l - 1) = %v, %v", out, err)
+	}
+	if _, err := s.SetCanonicalBytesBE(lMinusOneBE[1:]); err == nil {
+		t.Error("SetCanonicalBytesBE accepted a short input")
+	}
+}
+
+func TestScalarSetPaddedCanonicalBytes(t *testing.T) {
+	f := func(s Scalar, n uint8) bool {
+		padded := make([]byte, 32+int(n%16))
+		copy(padded, s.Bytes())
+		var out Scalar
+		if r, err := out.SetPaddedCanonicalBytes(padded); err != nil || r != &out {
+			return false
+		}
+		return out == s
+	}
+	if err := quick.Check(f, quickCheckConfig1024); err != nil {
+		t.Error(err)
+	}
+
+	// l is the smallest non-canonical value.
+	lPadded := make([]byte, 40)
+	lBE := Order().FillBytes(make([]byte, 32))
+	for i := range lBE {
+		lPadded[i] = lBE[31-i]
+	}
+
+	s := One()
+	for _, tt := range []struct {
+		name string
+		b    []byte
+	}{
+		{"short", make([]byte, 31)},
+		{"empty", nil},
+		{"nonzero padding", append(NewScalar().Bytes(), 0, 0, 0, 0, 0, 0, 0, 1)},
+		{"non-canonical", lPadded},
+	} {
+		if out, err := s.SetPaddedCanonicalBytes(tt.b); err == nil || out != nil {
+			t.Errorf("SetPaddedCanonicalBytes accepted %s input", tt.name)
+		}
+		if s.Equal(One()) != 1 {
+			t.Errorf("SetPaddedCanonicalBytes modified the receiver on %s input", tt.name)
+		}
+	}
+}
+
+func TestScalarMultBytesOut(t *testing.T) {
+	f := func(x, y Scalar) bool {
+		q := new(Point).ScalarBaseMult(&y)
+		want := new(Point).ScalarMult(&x, q)
+
+		var v Point
+		got := v.ScalarMultBytesOut(&x, q)
+		if !bytes.Equal(got, want.Bytes()) || v.Equal(want) != 1 {
+			return false
+		}
+		// The receiver may alias q.
+		return bytes.Equal(q.ScalarMultBytesOut(&x, q), got)
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScalarMultBytes(t *testing.T) {
+	f := func(x Scalar) bool {
+		var want, got Point
+		want.ScalarMult(&x, B)
+		out, err := got.ScalarMultBytes(x.Bytes(), B)
+		return err == nil && out == &got && got.Equal(&want) == 1
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	p := new(Point).Set(B)
+	lBytes := scMinusOne.Bytes()
+	lBytes[0]++
+	for _, k := range [][]byte{lBytes, make([]byte, 31), make([]byte, 33)} {
+		if out, err := p.ScalarMultBytes(k, B); err == nil || out != nil {
+			t.Errorf("ScalarMultBytes(%x) did not fail", k)
+		}
+		if p.Equal(B) != 1 {
+			t.Error("ScalarMultBytes modified the receiver on error")
+		}
+	}
+}
+
+func TestBytesMany(t *testing.T) {
+	f := func(x [4]Scalar) bool {
+		var points []*Point
+		for i := range x {
+			points = append(points, new(Point).ScalarBaseMult(&x[i]))
+		}
+		b := BytesMany(points)
+		if len(b) != 32*len(points) {
+			return false
+		}
+		for i, p := range points {
+			if !bytes.Equal(b[32*i:32*(i+1)], p.Bytes()) {
+				return false
+			}
+		}
+
+		out, err := SetBytesMany(len(points), b)
+		if err != nil || len(out) != len(points) {
+			return false
+		}
+		for i := range out {
+			if out[i].Equal(points[i]) != 1 {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	if out, err := SetBytesMany(0, nil); err != nil || len(out) != 0 {
+		t.Errorf("SetBytesMany(0, nil) = %v, %v", out, err)
+	}
+	b := BytesMany([]*Point{B, I, B})
+	if _, err := SetBytesMany(2, b); err == nil {
+		t.Error("expected error for length mismatch")
+	}
+	if _, err := SetBytesMany(3, b[:95]); err == nil {
+		t.Error("expected error for truncated input")
+	}
+
+	// y = 2 is not on the curve.
+	invalid := decodeHex("0200000000000000000000000000000000000000000000000000000000000000")
+	copy(b[64:], invalid)
+	out, err := SetBytesMany(3, b)
+	if err == nil || out != nil {
+		t.Fatal("expected error for invalid point")
+	}
+	if !strings.Contains(err.Error(), "point 2") {
+		t.Errorf("error does not identify the invalid point: %v", err)
+	}
+}
+
+func TestMarshalTagged(t *testing.T) {
+	f := func(s Scalar, x Scalar) bool {
+		p := new(Point).ScalarBaseMult(&x)
+
+		b, err := MarshalTagged(&s)
+		if err != nil || len(b) != 33 || b[0] != 0x01 || !bytes.Equal(b[1:], s.Bytes()) {
+			return false
+		}
+		v, err := UnmarshalTagged(b)
+		if err != nil {
+			return false
+		}
+		if got, ok := v.(*Scalar); !ok || got.Equal(&s) != 1 {
+			return false
+		}
+
+		b, err = MarshalTagged(p)
+		if err != nil || len(b) != 33 || b[0] != 0x02 || !bytes.Equal(b[1:], p.Bytes()) {
+			return false
+		}
+		v, err = UnmarshalTagged(b)
+		if err != nil {
+			return false
+		}
+		got, ok := v.(*Point)
+		return ok && got.Equal(p) == 1
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnmarshalTaggedErrors(t *testing.T) {
+	if _, err := MarshalTagged(Scalar{}); err == nil {
+		t.Error("expected error for non-pointer Scalar")
+	}
+	if _, err := MarshalTagged([]byte{1, 2, 3}); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+	if _, err := MarshalTagged((*Scalar)(nil)); err == nil {
+		t.Error("expected error for nil *Scalar")
+	}
+	if _, err := MarshalTagged((*Point)(nil)); err == nil {
+		t.Error("expected error for nil *Point")
+	}
+
+	scalar, _ := MarshalTagged(&scOne)
+	point, _ := MarshalTagged(B)
+	for _, tag := range []byte{0x00, 0x03, 0xff} {
+		b := append([]byte{tag}, scalar[1:]...)
+		if v, err := UnmarshalTagged(b); err == nil || v != nil {
+			t.Errorf("UnmarshalTagged accepted tag %#02x", tag)
+		}
+	}
+	if _, err := UnmarshalTagged(scalar[:32]); err == nil {
+		t.Error("expected error for truncated input")
+	}
+	if _, err := UnmarshalTagged(nil); err == nil {
+		t.Error("expected error for empty input")
+	}
+
+	// Swapping the tags decodes the data as the wrong type, and fails if the
+	// data is not a valid encoding of that type.
+	notOnCurve := append([]byte{0x02}, decodeHex("0200000000000000000000000000000000000000000000000000000000000000")...)
+	if _, err := UnmarshalTagged(notOnCurve); err == nil {
+		t.Error("expected error for invalid point")
+	}
+	point[0] = 0x01
+	if _, err := UnmarshalTagged(point); err == nil {
+		t.Error("expected error for non-canonical scalar")
+	}
+}
+
+// lowOrderEncodings are the canonical encodings of the eight points of order
+// dividing 8.
+var lowOrderEncodings = []string{
+	"0100000000000000000000000000000000000000000000000000000000000000", // order 1
+	"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", // order 2
+	"0000000000000000000000000000000000000000000000000000000000000000", // order 4
+	"0000000000000000000000000000000000000000000000000000000000000080", // order 4
+	"26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc05", // order 8
+	"26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc85", // order 8
+	"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a", // order 8
+	"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac03fa", // order 8
+}
+
+func TestIsLowOrderEncoding(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, enc := range lowOrderEncodings {
+		p, err := new(Point).SetBytes(decodeHex(enc))
+		if err != nil {
+			t.Fatalf("%s: %v", enc, err)
+		}
+		if got := hex.EncodeToString(p.Bytes()); got != enc {
+			t.Errorf("%s is not canonical, re-encodes to %s", enc, got)
+		}
+		if new(Point).MultByCofactor(p).Equal(I) != 1 {
+			t.Errorf("%s: 8 * P is not the identity", enc)
+		}
+		if !IsLowOrderEncoding(decodeHex(enc)) {
+			t.Errorf("IsLowOrderEncoding(%s) = false", enc)
+		}
+		seen[enc] = true
+	}
+	if len(seen) != 8 {
+		t.Errorf("expected 8 distinct encodings, got %d", len(seen))
+	}
+
+	// Non-canonical encodings of low-order points are detected too.
+	for _, enc := range []string{
+		"0100000000000000000000000000000000000000000000000000000000000080", // y=1,sign-
+		"eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", // y=p+1
+		"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", // y=p
+	} {
+		if !IsLowOrderEncoding(decodeHex(enc)) {
+			t.Errorf("IsLowOrderEncoding(%s) = false", enc)
+		}
+	}
+
+	if IsLowOrderEncoding(B.Bytes()) {
+		t.Error("IsLowOrderEncoding(B) = true")
+	}
+	// A low-order point plus B is not low-order.
+	p, _ := new(Point).SetBytes(decodeHex(lowOrderEncodings[4]))
+	if IsLowOrderEncoding(p.Add(p, B).Bytes()) {
+		t.Error("IsLowOrderEncoding(T + B) = true")
+	}
+	if IsLowOrderEncoding(decodeHex("0200000000000000000000000000000000000000000000000000000000000000")) {
+		t.Error("IsLowOrderEncoding accepted an invalid encoding")
+	}
+	if IsLowOrderEncoding(make([]byte, 31)) {
+		t.Error("IsLowOrderEncoding accepted a short encoding")
+	}
+}
+
+func TestDivByCofactor(t *testing.T) {
+	eight := scalarFromUint64(8)
+	if new(Scalar).Multiply(eight, &scInvEight).Equal(&scOne) != 1 {
+		t.Fatal("scInvEight is not the inverse of 8")
+	}
+
+	f := func(x Scalar) bool {
+		p := new(Point).ScalarBaseMult(&x)
+		q := new(Point).MultByCofactor(p)
+		if new(Point).DivByCofactor(q).Equal(p) != 1 {
+			return false
+		}
+		if new(Point).MultByCofactor(new(Point).DivByCofactor(p)).Equal(p) != 1 {
+			return false
+		}
+		// The receiver may alias q.
+		return q.DivByCofactor(q).Equal(p) == 1
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEqualUpToCofactor(t *testing.T) {
+	var torsion []*Point
+	for _, enc := range lowOrderEncodings {
+		p, err := new(Point).SetBytes(decodeHex(enc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		torsion = append(torsion, p)
+	}
+
+	f := func(x, y Scalar) bool {
+		p := new(Point).ScalarBaseMult(&x)
+		for _, tp := range torsion {
+			q := new(Point).Add(p, tp)
+			if p.EqualUpToCofactor(q) != 1 || q.EqualUpToCofactor(p) != 1 {
+				return false
+			}
+			if q.Equal(p) != tp.IsIdentity() {
+				return false
+			}
+		}
+		q := new(Point).ScalarBaseMult(&y)
+		q.Add(q, torsion[4])
+		return p.EqualUpToCofactor(q) == p.Equal(new(Point).Subtract(q, torsion[4]))
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	if B.EqualUpToCofactor(I) != 0 {
+		t.Error("B is equal to the identity up to the cofactor")
+	}
+	for _, tp := range torsion {
+		if tp.EqualUpToCofactor(I) != 1 {
+			t.Error("a small-order point is not equal to the identity up to the cofactor")
+		}
+	}
+}
+
+func TestIsIdentity(t *testing.T) {
+	if NewIdentityPoint().IsIdentity() != 1 {
+		t.Error("the identity is not the identity")
+	}
+	if NewGeneratorPoint().IsIdentity() != 0 {
+		t.Error("the generator is the identity")
+	}
+
+	// The identity in a representation with Z != 1.
+	p := new(Point).Add(B, B)
+	p.Subtract(p, B)
+	p.Subtract(p, B)
+	if p.IsIdentity() != 1 {
+		t.Error("2B - B - B is not the identity")
+	}
+
+	// The other low-order points are not the identity.
+	for _, enc := range lowOrderEncodings[1:] {
+		q, _ := new(Point).SetBytes(decodeHex(enc))
+		if q.IsIdentity() != 0 {
+			t.Errorf("%s is the identity", enc)
+		}
+	}
+
+	f := func(x Scalar) bool {
+		q := new(Point).ScalarBaseMult(&x)
+		return q.IsIdentity() == q.Equal(I)
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSmallOrderFactor(t *testing.T) {
+	orders := []int{1, 2, 4, 4, 8, 8, 8, 8}
+	for i, enc := range lowOrderEncodings {
+		p, err := new(Point).SetBytes(decodeHex(enc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.SmallOrderFactor(); got != orders[i] {
+			t.Errorf("SmallOrderFactor(%s) = %d, want %d", enc, got, orders[i])
+		}
+
+		// Adding a prime-order component makes the order large.
+		if got := p.Add(p, B).SmallOrderFactor(); got != 0 {
+			t.Errorf("SmallOrderFactor(%s + B) = %d, want 0", enc, got)
+		}
+	}
+
+	f := func(x Scalar) bool {
+		p := new(Point).ScalarBaseMult(&x)
+		if x.Equal(NewScalar()) == 1 {
+			return p.SmallOrderFactor() == 1
+		}
+		return p.SmallOrderFactor() == 0
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExpandMessageXMD(t *testing.T) {
+	// From RFC 9380, Appendix K.3.
+	dst := []byte("QUUX-V01-CS02-with-expander-SHA512-256")
+	tests := []struct {
+		msg  string
+		len  int
+		want string
+	}{
+		{"", 0x20, "6b9a7312411d92f921c6f68ca0b6380730a1a4d982c507211a90964c394179ba"},
+		{"abc", 0x20, "0da749f12fbe5483eb066a5f595055679b976e93abe9be6f0f6318bce7aca8dc"},
+		{"abcdef0123456789", 0x20, "087e45a86e2939ee8b91100af1583c4938e0f5fc6c9db4b107b83346bc967f58"},
+		{"", 0x80, "41b037d1734a5f8df225dd8c7de38f851efdb45c372887be655212d07251b921" +
+			"b052b62eaed99b46f72f2ef4cc96bfaf254ebbbec091e1a3b9e4fb5e5b619d2e" +
+			"0c5414800a1d882b62bb5cd1778f098b8eb6cb399d5d9d18f5d5842cf5d13d7e" +
+			"b00a7cff859b605da678b318bd0e65ebff70bec88c753b159a805d2c89c55961"},
+		{"abc", 0x80, "7f1dddd13c08b543f2e2037b14cefb255b44c83cc397c1786d975653e36a6b11" +
+			"bdd7732d8b38adb4a0edc26a0cef4bb45217135456e58fbca1703cd6032cb134" +
+			"7ee720b87972d63fbf232587043ed2901bce7f22610c0419751c065922b48843" +
+			"1851041310ad659e4b23520e1772ab29dcdeb2002222a363f0c2b1c972b3efe1"},
+	}
+	for _, tt := range tests {
+		out := make([]byte, tt.len)
+		expandMessageXMD(out, []byte(tt.msg), dst)
+		if got := hex.EncodeToString(out); got != tt.want {
+			t.Errorf("expand_message_xmd(%q, %d) = %s, want %s", tt.msg, tt.len, got, tt.want)
+		}
+	}
+}
+
+func TestScalarSetHashToField(t *testing.T) {
+	// Computed with an independent implementation of hash_to_field.
+	dst := []byte("QUUX-V01-CS02-with-edwards25519_XMD:SHA-512_SCALAR")
+	tests := []struct {
+		msg, want string
+	}{
+		{"", "63a8b6a596970faaa3dc9a98fc8e61a44281b1bda9b20d0085e45b21c9a99d0f"},
+		{"abc", "02ef02e27e453dc6ad9f8c5266d09d13d198591d5aad590edfebf73e1c0d1b0c"},
+		{"abcdef0123456789", "bb614a33630a1a7f5fc20723afb556023fbf9e588155d0c70dd6f057bb2c3d06"},
+	}
+	for _, tt := range tests {
+		s := NewScalar().SetHashToField([]byte(tt.msg), dst)
+		if got := hex.EncodeToString(s.Bytes()); got != tt.want {
+			t.Errorf("SetHashToField(%q) = %s, want %s", tt.msg, got, tt.want)
+		}
+	}
+
+	// Tags longer than 255 bytes are hashed first.
+	longDST := bytes.Repeat([]byte("a"), 300)
+	s := NewScalar().SetHashToField([]byte("abc"), longDST)
+	want := "32a25826d3ca44bb9a4bb0627d630e455d883318b1a93ff71e04d98c70389008"
+	if got := hex.EncodeToString(s.Bytes()); got != want {
+		t.Errorf("SetHashToField with long DST = %s, want %s", got, want)
+	}
+}
+
+func TestTranscript(t *testing.T) {
+	newTranscript := func() *Transcript {
+		tr := NewTranscript("test protocol")
+		tr.AppendPoint("point", B)
+		tr.AppendScalar("scalar", One())
+		return tr
+	}
+
+	// Computed with an independent implementation of the encoding.
+	tr := newTranscript()
+	c1 := tr.ChallengeScalar("challenge")
+	c2 := tr.ChallengeScalar("challenge")
+	if got, want := hex.EncodeToString(c1.Bytes()),
+		"f7b30589b95991e9e294c28df325250f59d40a45d6cf318492685a685f2ad603"; got != want {
+		t.Errorf("first challenge = %s, want %s", got, want)
+	}
+	if got, want := hex.EncodeToString(c2.Bytes()),
+		"e4e9ccaa67209421609aaee4d0ce84b2b13892b3c0760532b3549fa2ceaaf701"; got != want {
+		t.Errorf("second challenge = %s, want %s", got, want)
+	}
+
+	// Changing any label, the domain, or any value changes the challenge.
+	variants := []*Transcript{
+		NewTranscript("other protocol"),
+		NewTranscript("test protocol"),
+		NewTranscript("test protocol"),
+		NewTranscript("test protocol"),
+	}
+	variants[0].AppendPoint("point", B)
+	variants[0].AppendScalar("scalar", One())
+	variants[1].AppendPoint("Point", B)
+	variants[1].AppendScalar("scalar", One())
+	variants[2].AppendPoint("point", I)
+	variants[2].AppendScalar("scalar", One())
+	variants[3].AppendPoint("point", B)
+	variants[3].AppendScalar("scalar", NewScalar())
+	for i, v := range variants {
+		if v.ChallengeScalar("challenge").Equal(c1) == 1 {
+			t.Errorf("variant %d produced the same challenge", i)
+		}
+	}
+	if newTranscript().ChallengeScalar("other").Equal(c1) == 1 {
+		t.Error("challenge label did not change the challenge")
+	}
+}
+
+func TestScalarMulMany(t *testing.T) {
+	f := func(a, b, c, d Scalar) bool {
+		var want Scalar
+		want.Multiply(&a, &b)
+		want.Multiply(&want, &c)
+		want.Multiply(&want, &d)
+
+		var got Scalar
+		if got.MulMany(&a, &b, &c, &d) != &got || got != want {
+			return false
+		}
+		if got.MulMany(&a) != &got || got != a {
+			return false
+		}
+		// The receiver may alias a factor.
+		return c.MulMany(&a, &b, &c, &d) == &c && c == want
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	s := NewScalar().Set(&dalekScalar)
+	if s.MulMany().Equal(One()) != 1 {
+		t.Error("empty MulMany is not one")
+	}
+}
+
+func TestScalarAssign(t *testing.T) {
+	f := func(a, b Scalar) bool {
+		for _, tt := range []struct {
+			assign func(s, x *Scalar) *Scalar
+			op     func(s, x, y *Scalar) *Scalar
+		}{
+			{(*Scalar).AddAssign, (*Scalar).Add},
+			{(*Scalar).SubAssign, (*Scalar).Subtract},
+			{(*Scalar).MulAssign, (*Scalar).Multiply},
+		} {
+			var want Scalar
+			tt.op(&want, &a, &b)
+			got := a
+			if tt.assign(&got, &b) != &got || got != want {
+				return false
+			}
+			// x may alias the receiver.
+			tt.op(&want, &a, &a)
+			got = a
+			if tt.assign(&got, &got) != &got || got != want {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, quickCheckConfig1024); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPointBlind(t *testing.T) {
+	f := func(x Scalar) bool {
+		p := new(Point).ScalarBaseMult(&x)
+		orig := *p
+		if err := p.Blind(rand.Reader); err != nil {
+			return false
+		}
+		checkOnCurve(t, p)
+		return p.Equal(&orig) == 1 && bytes.Equal(p.Bytes(), orig.Bytes()) &&
+			p.y.Equal(&orig.y) == 0 && p.z.Equal(&orig.z) == 0
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	p := NewGeneratorPoint()
+	orig := *p
+	err := p.Blind(bytes.NewReader(make([]byte, 63)))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if p.x != orig.x || p.y != orig.y || p.z != orig.z || p.t != orig.t {
+		t.Error("Blind modified the receiver on error")
+	}
+
+	// A zero blinding factor is skipped.
+	r := append(make([]byte, 64), bytes.Repeat([]byte{1}, 64)...)
+	if err := p.Blind(bytes.NewReader(r)); err != nil || p.Equal(B) != 1 || p.z.Equal(&orig.z) == 1 {
+		t.Errorf("Blind with a zero factor first: %v", err)
+	}
+}
+
+func TestGeneratorMultiples(t *testing.T) {
+	for _, k := range []int{-1, 0} {
+		if out := GeneratorMultiples(k); len(out) != 0 {
+			t.Errorf("GeneratorMultiples(%d) returned %d points", k, len(out))
+		}
+	}
+
+	out := GeneratorMultiples(20)
+	if len(out) != 20 {
+		t.Fatalf("GeneratorMultiples(20) returned %d points", len(out))
+	}
+	for i, p := range out {
+		checkOnCurve(t, p)
+		want := new(Point).ScalarBaseMult(scalarFromUint64(uint64(i + 1)))
+		if p.Equal(want) != 1 {
+			t.Errorf("GeneratorMultiples(20)[%d] != %d * B", i, i+1)
+		}
+	}
+
+	// The returned points are independent of each other and of the generator.
+	out[0].Add(out[0], out[1])
+	if out[1].Equal(new(Point).Add(B, B)) != 1 || NewGeneratorPoint().Equal(B) != 1 {
+		t.Error("GeneratorMultiples returned aliased points")
+	}
+}
+
+func TestPowerMultiples(t *testing.T) {
+	f := func(s, x Scalar) bool {
+		base := new(Point).ScalarBaseMult(&x)
+		dst := make([]*Point, 5)
+		for i := range dst {
+			dst[i] = new(Point)
+		}
+		if err := PowerMultiples(dst, &s, base); err != nil {
+			return false
+		}
+		want := new(Point).Set(base)
+		for i := range dst {
+			want.ScalarMult(&s, want)
+			if dst[i].Equal(want) != 1 {
+				return false
+			}
+		}
+
+		// base may alias an element of dst.
+		dst[0].Set(base)
+		if err := PowerMultiples(dst, &s, dst[0]); err != nil {
+			return false
+		}
+		return dst[4].Equal(want) == 1
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	if err := PowerMultiples(nil, One(), B); err == nil {
+		t.Error("expected error for empty dst")
+	}
+}
+
+func TestConstantTimeEqualEncoding(t *testing.T) {
+	f := func(x, y Scalar) bool {
+		a := new(Point).ScalarBaseMult(&x).Bytes()
+		b := new(Point).ScalarBaseMult(&y).Bytes()
+		want := 0
+		if bytes.Equal(a, b) {
+			want = 1
+		}
+		return ConstantTimeEqualEncoding(a, b) == want &&
+			ConstantTimeEqualEncoding(a, append([]byte{}, a...)) == 1
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	g := B.Bytes()
+	for _, tt := range []struct {
+		name string
+		a, b []byte
+	}{
+		{"short", g[:31], g[:31]},
+		{"long", append(g, 0), append(g, 0)},
+		{"mismatched", g, append(g, 0)},
+		{"empty", nil, nil},
+		{"different", g, I.Bytes()},
+	} {
+		if ConstantTimeEqualEncoding(tt.a, tt.b) != 0 {
+			t.Errorf("ConstantTimeEqualEncoding returned 1 for %s inputs", tt.name)
+		}
+	}
+}
+
+func TestValidPointEncoding(t *testing.T) {
+	f := func(in [32]byte) bool {
+		_, err := new(Point).SetBytes(in[:])
+		return ValidPointEncoding(in[:]) == (err == nil)
+	}
+	if err := quick.Check(f, quickCheckConfig1024); err != nil {
+		t.Error(err)
+	}
+
+	for _, enc := range []string{
+		"5866666666666666666666666666666666666666666666666666666666666666", // B
+		"0100000000000000000000000000000000000000000000000000000000000000", // identity
+		"0100000000000000000000000000000000000000000000000000000000000080", // y=1,sign-
+		"eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f", // y=p+1
+	} {
+		if !ValidPointEncoding(decodeHex(enc)) {
+			t.Errorf("ValidPointEncoding(%s) = false", enc)
+		}
+	}
+	for _, enc := range []string{
+		"0200000000000000000000000000000000000000000000000000000000000000", // y=2 is not on the curve
+		"5866666666666666666666666666666666666666666666666666666666666666ff",
+		"58666666666666666666666666666666666666666666666666666666666666",
+	} {
+		if ValidPointEncoding(decodeHex(enc)) {
+			t.Errorf("ValidPointEncoding(%s) = true", enc)
+		}
+	}
+}
+
+func TestNewScalarPowers(t *testing.T) {
+	f := func(x Scalar) bool {
+		powers := NewScalarPowers(&x, 6)
+		if len(powers) != 6 {
+			return false
+		}
+		want := One()
+		for i := range powers {
+			if powers[i].Equal(want) != 1 {
+				return false
+			}
+			want.Multiply(want, &x)
+		}
+		return true
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	if powers := NewScalarPowers(&dalekScalar, 0); powers != nil {
+		t.Errorf("n = 0: got %d powers", len(powers))
+	}
+	if powers := NewScalarPowers(&dalekScalar, -1); powers != nil {
+		t.Errorf("n = -1: got %d powers", len(powers))
+	}
+	if powers := NewScalarPowers(&dalekScalar, 1); len(powers) != 1 || powers[0].Equal(One()) != 1 {
+		t.Errorf("n = 1: got %v", powers)
+	}
+}
+
+func TestEvalPoly(t *testing.T) {
+	f := func(c [5]Scalar, x Scalar) bool {
+		var coeffs []*Scalar
+		for i := range c {
+			coeffs = append(coeffs, &c[i])
+		}
+		powers := NewScalarPowers(&x, len(coeffs))
+		for n := 0; n <= len(coeffs); n++ {
+			var want Scalar
+			if err := ScalarDotProduct(&want, coeffs[:n], powers[:n]); err != nil {
+				return false
+			}
+			if EvalPoly(coeffs[:n], &x).Equal(&want) != 1 {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	if EvalPoly(nil, &dalekScalar).Equal(NewScalar()) != 1 {
+		t.Error("empty polynomial does not evaluate to zero")
+	}
+}
+
+func TestCommitPoly(t *testing.T) {
+	f := func(c [4]Scalar, tau Scalar) bool {
+		var coeffs []*Scalar
+		for i := range c {
+			coeffs = append(coeffs, &c[i])
+		}
+		var powersOfTau []*Point
+		for _, power := range NewScalarPowers(&tau, len(coeffs)) {
+			powersOfTau = append(powersOfTau, new(Point).ScalarBaseMult(power))
+		}
+
+		got, err := CommitPoly(coeffs, powersOfTau)
+		if err != nil {
+			return false
+		}
+		want := NewIdentityPoint()
+		for i := range coeffs {
+			want.Add(want, new(Point).ScalarMult(coeffs[i], powersOfTau[i]))
+		}
+		if got.Equal(want) != 1 {
+			return false
+		}
+
+		// The commitment is the polynomial evaluated at tau, times B.
+		return got.Equal(new(Point).ScalarBaseMult(EvalPoly(coeffs, &tau))) == 1
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	if p, err := CommitPoly(nil, nil); err != nil || p.Equal(I) != 1 {
+		t.Errorf("empty commitment: got %v, %v", p, err)
+	}
+	if p, err := CommitPoly([]*Scalar{One()}, []*Point{B, B}); err == nil || p != nil {
+		t.Error("expected error for different size inputs")
+	}
+}
+
+func TestMultiScalarMultMatchesVarTime(t *testing.T) {
+	f := func(x [4]Scalar, y [4]Scalar) bool {
+		for n := 0; n <= len(x); n++ {
+			var scalars []*Scalar
+			var points []*Point
+			for i := 0; i < n; i++ {
+				scalars = append(scalars, &x[i])
+				points = append(points, new(Point).ScalarBaseMult(&y[i]))
+			}
+			var p, q Point
+			p.MultiScalarMult(scalars, points)
+			q.VarTimeMultiScalarMult(scalars, points)
+			checkOnCurve(t, &p, &q)
+			if p.Equal(&q) != 1 {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScalarSetEd25519Seed(t *testing.T) {
+	message := []byte("test message")
+	f := func(seed [32]byte) bool {
+		var s Scalar
+		prefix, err := s.SetEd25519Seed(seed[:])
+		if err != nil || len(prefix) != 32 {
+			return false
+		}
+
+		priv := ed25519.NewKeyFromSeed(seed[:])
+		pub := new(Point).ScalarBaseMult(&s).Bytes()
+		if !bytes.Equal(pub, priv.Public().(ed25519.PublicKey)) {
+			return false
+		}
+
+		// The nonce of an Ed25519 signature is SHA-512(prefix || message),
+		// and the signature starts with the encoding of nonce * B.
+		h := sha512.New()
+		h.Write(prefix)
+		h.Write(message)
+		r, _ := NewScalar().SetUniformBytes(h.Sum(nil))
+		R := new(Point).ScalarBaseMult(r).Bytes()
+		return bytes.Equal(R, ed25519.Sign(priv, message)[:32])
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	// RFC 8032, Section 7.1, TEST 1.
+	seed := decodeHex("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	s := NewScalar()
+	if _, err := s.SetEd25519Seed(seed); err != nil {
+		t.Fatal(err)
+	}
+	want := "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+	if got := hex.EncodeToString(new(Point).ScalarBaseMult(s).Bytes()); got != want {
+		t.Errorf("public key = %s, want %s", got, want)
+	}
+
+	s = One()
+	if prefix, err := s.SetEd25519Seed(seed[:31]); err == nil || prefix != nil {
+		t.Error("expected error for short seed")
+	}
+	if s.Equal(One()) != 1 {
+		t.Error("SetEd25519Seed modified the receiver on error")
+	}
+}
+
+func TestCanonicalEncodingRoundTrips(t *testing.T) {
+	f := func(x Scalar) bool {
+		p := new(Point).ScalarBaseMult(&x)
+		return p.CanonicalEncodingRoundTrips()
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	if !NewIdentityPoint().CanonicalEncodingRoundTrips() {
+		t.Error("identity does not round-trip")
+	}
+	for _, enc := range lowOrderEncodings {
+		p, _ := new(Point).SetBytes(decodeHex(enc))
+		if !p.CanonicalEncodingRoundTrips() {
+			t.Errorf("%s does not round-trip", enc)
+		}
+	}
+
+	// A point with a corrupted x coordinate is not on the curve, and its
+	// encoding decodes to a different point, if any.
+	p := NewGeneratorPoint()
+	p.x.Add(&p.x, feOne)
+	if p.CanonicalEncodingRoundTrips() {
+		t.Error("corrupted point round-trips")
+	}
+}
+
+func scalarFromUint64(x uint64) *Scalar {
+	var s Scalar
+	binary.LittleEndian.PutUint64(s.s[:8], x)
+	return &s
+}
+
+func TestScalarBitLen(t *testing.T) {
+	for _, tt := range []struct {
+		s    *Scalar
+		want int
+	}{
+		{NewScalar(), 0},
+		{&scOne, 1},
+		{&scMinusOne, 253},
+		{scalarFromUint64(255), 8},
+		{scalarFromUint64(256), 9},
+	} {
+		if got := tt.s.BitLen(); got != tt.want {
+			t.Errorf("BitLen(%v) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+
+	f := func(s Scalar) bool {
+		return s.BitLen() == bigIntFromLittleEndianBytes(s.Bytes()).BitLen()
+	}
+	if err := quick.Check(f, quickCheckConfig1024); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNAFWeight(t *testing.T) {
+	for _, tt := range []struct {
+		s    *Scalar
+		w    uint
+		want int
+	}{
+		{NewScalar(), 5, 0},
+		{&scOne, 5, 1},
+		// 2^8 - 1 = 2^8 - 2^0 in width-2 NAF.
+		{scalarFromUint64(255), 2, 2},
+		// 0b10101 has no adjacent nonzero bits, so its NAF is its binary.
+		{scalarFromUint64(21), 2, 3},
+	} {
+		naf, err := tt.s.NonAdjacentFormChecked(tt.w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := NAFWeight(naf); got != tt.want {
+			t.Errorf("NAFWeight(NAF_%d(%v)) = %d, want %d", tt.w, tt.s, got, tt.want)
+		}
+	}
+
+	// Any two nonzero digits of a width-w NAF are at least w positions apart.
+	f := func(s Scalar) bool {
+		for w := uint(2); w <= 8; w++ {
+			naf, err := s.NonAdjacentFormChecked(w)
+			if err != nil {
+				return false
+			}
+			n := s.BitLen()
+			if NAFWeight(naf) > (n+int(w))/int(w) {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNonAdjacentFormChecked(t *testing.T) {
+	f := func(x Scalar) bool {
+		for w := uint(2); w <= 8; w++ {
+			naf, err := x.NonAdjacentFormChecked(w)
+			if err != nil || naf != x.nonAdjacentForm(w) {
+				return false
+			}
+
+			// Reconstruct x from the digits, and check their properties.
+			sum := new(big.Int)
+			for i := len(naf) - 1; i >= 0; i-- {
+				sum.Lsh(sum, 1)
+				sum.Add(sum, big.NewInt(int64(naf[i])))
+				if d := int(naf[i]); d != 0 {
+					if d%2 == 0 || d >= 1<<(w-1) || d <= -(1<<(w-1)) {
+						return false
+					}
+					for j := i + 1; j < i+int(w) && j < len(naf); j++ {
+						if naf[j] != 0 {
+							return false
+						}
+					}
+				}
+			}
+			if sum.Cmp(bigIntFromLittleEndianBytes(x.Bytes())) != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	for _, w := range []uint{0, 1, 9, 64} {
+		if _, err := dalekScalar.NonAdjacentFormChecked(w); err == nil {
+			t.Errorf("expected error for w = %d", w)
+		}
+	}
+	highBit := Scalar{}
+	highBit.s[31] = 0x80
+	if _, err := highBit.NonAdjacentFormChecked(5); err == nil {
+		t.Error("expected error for scalar with the high bit set")
+	}
+}
+
+func TestNewPointFromCoordinates(t *testing.T) {
+	f := func(x, r Scalar) bool {
+		p := new(Point).ScalarBaseMult(&x)
+		// Use a random representation of the same point.
+		var rr field.Element
+		rr.SetBytes(r.Bytes())
+		if rr.Equal(new(field.Element).Zero()) == 1 {
+			rr.One()
+		}
+		X, Y, Z, T := p.ExtendedCoordinates()
+		X.Multiply(X, &rr)
+		Y.Multiply(Y, &rr)
+		Z.Multiply(Z, &rr)
+		T.Multiply(T, &rr)
+
+		q, err := NewPointFromCoordinates(X.Bytes(), Y.Bytes(), Z.Bytes(), T.Bytes())
+		if err != nil || q.Equal(p) != 1 {
+			return false
+		}
+
+		// Inconsistent coordinates are rejected.
+		T.Add(T, feOne)
+		_, err = NewPointFromCoordinates(X.Bytes(), Y.Bytes(), Z.Bytes(), T.Bytes())
+		return err != nil
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	zero, one := make([]byte, 32), feOne.Bytes()
+	if p, err := NewPointFromCoordinates(zero, one, one, zero); err != nil || p.Equal(I) != 1 {
+		t.Errorf("identity: got %v, %v", p, err)
+	}
+	// All zero coordinates satisfy the curve equations, but don't represent a
+	// point.
+	if _, err := NewPointFromCoordinates(zero, zero, zero, zero); err == nil {
+		t.Error("expected error for Z = 0")
+	}
+	// y = 2 is not on the curve.
+	two := new(field.Element).Add(feOne, feOne).Bytes()
+	if _, err := NewPointFromCoordinates(zero, two, one, zero); err == nil {
+		t.Error("expected error for off-curve point")
+	}
+	// p + 1 is a non-canonical encoding of 1.
+	pPlusOne := decodeHex("eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	if _, err := NewPointFromCoordinates(zero, one, pPlusOne, zero); err == nil {
+		t.Error("expected error for non-canonical coordinate")
+	} else if !strings.Contains(err.Error(), "Z coordinate") {
+		t.Errorf("error does not name the Z coordinate: %v", err)
+	}
+	if _, err := NewPointFromCoordinates(zero, one, one, zero[:31]); err == nil {
+		t.Error("expected error for short coordinate")
+	}
+}
+
+func TestBytesSignBit(t *testing.T) {
+	f := func(s Scalar) bool {
+		p := new(Point).ScalarBaseMult(&s)
+		x, y := p.AffineBytes()
+		enc := p.Bytes()
+		if enc[31]>>7 != x[0]&1 {
+			return false
+		}
+		enc[31] &= 0x7f
+		if !bytes.Equal(enc, y) {
+			return false
+		}
+		// The negation differs only in the sign bit, unless x is zero.
+		neg := new(Point).Negate(p).Bytes()
+		return neg[31]>>7 == (x[0]&1)^1 || bytes.Equal(x, make([]byte, 32))
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScalarSetBigEndianModOrder(t *testing.T) {
+	two512 := new(big.Int).Lsh(big.NewInt(1), 512)
+	if got := bigIntFromLittleEndianBytes(scTwo512.Bytes()); got.Cmp(new(big.Int).Mod(two512, Order())) != 0 {
+		t.Errorf("scTwo512 = %v", got)
+	}
+
+	f := func(in []byte) bool {
+		for _, n := range []int{0, 1, 31, 32, 33, 63, 64, 65, 100, 128, 200} {
+			b := make([]byte, n)
+			copy(b, in)
+			for i := len(in); i < n; i++ {
+				b[i] = byte(i * 37)
+			}
+			s := One().SetBigEndianModOrder(b)
+			want := new(big.Int).SetBytes(b)
+			want.Mod(want, Order())
+			if got := bigIntFromLittleEndianBytes(s.Bytes()); got.Cmp(want) != 0 {
+				t.Logf("length %d: got %v, want %v", n, got, want)
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+
+	if One().SetBigEndianModOrder(nil).Equal(NewScalar()) != 1 {
+		t.Error("empty input is not zero")
+	}
+	allOnes := bytes.Repeat([]byte{0xff}, 100)
+	want := new(big.Int).SetBytes(allOnes)
+	want.Mod(want, Order())
+	if got := bigIntFromLittleEndianBytes(NewScalar().SetBigEndianModOrder(allOnes).Bytes()); got.Cmp(want) != 0 {
+		t.Errorf("100 bytes of 0xff: got %v, want %v", got, want)
+	}
+}
+
+func TestElligator2(t *testing.T) {
+	// The u values and the corresponding points before cofactor clearing, from
+	// the edwards25519_XMD:SHA-512_ELL2_NU_ and _RO_ suites of RFC 9380,
+	// Appendix J.5.
+	tests := []struct {
+		u, q string
+	}{
+		{"1d64304a37f0a0f793504c897d427b5d5032dff932db527fad038142b97f3e7f", "952ad4d663b2be28090685bb8baa07923c6a0d13d2620246bd235e55aa4acba2"},
+		{"3b256e551a20cde58be94db087671cb7f6763a5d0f4a595694d59bd70aa3cf09", "43342a7b7811c5d02c978d252eb23a276e2cb806e320c882a7c408eb78f1b6d1"},
+		{"41a9db3ed375de378942c4c00776b1b76b6a9f8e33c98cd790ef2592f9cf5c47", "f088faa6bef3998fc091a3ebf76e4938ef0763614f728f6a2542ee65c2a39e5b"},
+		{"3dd095ac149892f6cd1ba963078db82814f51f7d389f33ec2acb1bd58b1c9a04", "f2dbdfc18e2057dbf834cedc67712171b1420365afa3f8c9bd39d783383502d1"},
+		{"a04fd250072f2a364abe81277b78aaee7e8d857ca5a3795bface37818a17b03c", "7d44b9bf302579caa8b5ab6059ae6797bbc01028f47a6cae18a16c56f139fbb8"},
+		{"3a3f202d71eec79a7907b0e20d38d5e7e674e1fa88ef6e8cf9b58c3c81f4fe03", "eb9fcfb43f979a433f5d7c8ca082330b68c602d6ba228d0468ed47cfc8bc15f3"},
+		{"750ca6e693e72af92bd96846676b5fe3faaa957768dc89f5c8907213dddd0b78", "96a1af9bfc392cc8c550959e818d89d02b0b42820cb528598a8d827414d87678"},
+		{"27c257bdf9789c67700f277a4ddf3419aaffec6be3c02ed0e7e441415c958150", "291fa0fa407240a79596d0c2e85c41efc2ef8b102d7fbc0aa9d12271cf26f4ab"},
+		{"765b920baa193146e52a2556b271c3211f36041ba3732527b678b3a917dc5b00", "32f45869e00c1185dcc0738e5b5d19d2ffa92967134ebbd70a4df81254bf5e28"},
+		{"b3d5786a634206c5b42404d6f2df320b9bcc5e226ecb1b87791b70bea3ba5e28", "af6bb4be6c69fe614b674c996c1507710b6a8a16905c0d390becb98d1eba03fb"},
+		{"311bb86097366bec9835cb2a924765fd44152da6d64b8edbfe58f60e6a3e252e", "c0a1f616d497a31fece21520e4f88f396514ea91aa869d1e96240a93966c0250"},
+		{"af21f33b533ca8d4b4b864cf425a90ace3f55e94e25269602a1fc43154d2ed4f", "75ec9b9a156c3383797714b71c106f6b0d6ad55f4f87f0666410894f2c19bb32"},
+		{"a98574d309c172ca513725112e5b2969d8b676827a098739841b80a51607f202", "cb2638cb9c90bf4130a74fb5e54bda6d4db764795950f0538c63db1052ec5afd"},
+		{"3ba34e4adf06ded5564e597b7fb2239df01b6049ba4af659bde906514ae0346e", "2650ab962869c92c9f664c08f8da95228578db7bdf2c68e070f5894c323da341"},
+		{"96fdf56840e866ff29372d30d07649b654198cebd5c5864bf453c09fb52c1c1c", "3a4bd97754eb8a8aa64352ca5dbbddbaea575c58d6d8421238d1b83c7caccce7"},
+	}
+	for _, tt := range tests {
+		u, err := new(field.Element).SetBytes(decodeHex(tt.u))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := elligator2(u)
+		checkOnCurve(t, p)
+		if got := hex.EncodeToString(p.Bytes()); got != tt.q {
+			t.Errorf("elligator2(%s) = %s, want %s", tt.u, got, tt.q)
+		}
+	}
+
+	// u = 0 maps to x1 = -A, where gx1 is not square, so x2 = 0 and the
+	// result is the identity through the exceptional case of the rational map.
+	if p := elligator2(new(field.Element)); p.Equal(I) != 1 {
+		t.Errorf("elligator2(0) = %v, want the identity", p)
+	}
+}
+
+func TestPointFromSeed(t *testing.T) {
+	seen := make(map[string]bool)
+	for seed := uint64(0); seed < 256; seed++ {
+		p := PointFromSeed(seed)
+		checkOnCurve(t, p)
+		enc := p.Bytes()
+		if !ValidPointEncoding(enc) {
+			t.Errorf("PointFromSeed(%d) is not a valid encoding", seed)
+		}
+		if p.IsIdentity() == 1 || new(Point).ScalarMult(&scMinusOne, p).Equal(new(Point).Negate(p)) != 1 {
+			t.Errorf("PointFromSeed(%d) is not in the prime-order subgroup", seed)
+		}
+		if seen[string(enc)] {
+			t.Errorf("PointFromSeed(%d) repeats an earlier point", seed)
+		}
+		seen[string(enc)] = true
+
+		if PointFromSeed(seed).Equal(p) != 1 {
+			t.Errorf("PointFromSeed(%d) is not deterministic", seed)
+		}
+	}
+}
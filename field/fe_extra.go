@@ -48,3 +48,12 @@ func (v *Element) SetWideBytes(x []byte) (*Element, error) {
 
 	return v.carryPropagate(), nil
 }
+
+// Sqrt sets r to the non-negative square root of u, if u is square.
+//
+// If u is square, Sqrt returns r and 1. If u is not square, Sqrt sets r to the
+// non-negative square root of sqrt(-1) * u, as SqrtRatio(u, 1) does, and
+// returns r and 0.
+func (r *Element) Sqrt(u *Element) (R *Element, wasSquare int) {
+	return r.SqrtRatio(u, new(Element).One())
+}
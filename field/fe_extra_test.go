@@ -35,3 +35,41 @@ func TestSetWideBytes(t *testing.T) {
 	}
 
 }
+
+func TestSqrt(t *testing.T) {
+	two := new(Element).Add(new(Element).One(), new(Element).One())
+
+	f := func(in [32]byte) bool {
+		var x, u, r Element
+		x.SetBytes(in[:])
+		u.Square(&x)
+		if out, wasSquare := r.Sqrt(&u); out != &r || wasSquare != 1 {
+			return false
+		}
+		// The root is the non-negative one of x and -x.
+		if r.IsNegative() != 0 || r.Equal(new(Element).Absolute(&x)) != 1 {
+			return false
+		}
+
+		// 2 is not square, so neither is 2 * x², unless x is zero.
+		u.Multiply(&u, two)
+		_, wasSquare := r.Sqrt(&u)
+		return wasSquare == x.Equal(new(Element).Zero()) && r.IsNegative() == 0
+	}
+	if err := quick.Check(f, quickCheckConfig1024); err != nil {
+		t.Error(err)
+	}
+
+	// Sqrt handles the aliased case.
+	four := new(Element).Add(two, two)
+	if r, wasSquare := four.Sqrt(four); wasSquare != 1 || r.Equal(two) != 1 {
+		t.Errorf("Sqrt(4) = %v, %d", r, wasSquare)
+	}
+	if r, wasSquare := new(Element).Sqrt(new(Element).Zero()); wasSquare != 1 ||
+		r.Equal(new(Element).Zero()) != 1 {
+		t.Errorf("Sqrt(0) = %v, %d", r, wasSquare)
+	}
+	if _, wasSquare := new(Element).Sqrt(two); wasSquare != 0 {
+		t.Error("Sqrt(2) reported a square")
+	}
+}
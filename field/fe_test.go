@@ -335,6 +335,21 @@ func TestEqual(t *testing.T) {
 	if eq != 0 {
 		t.Errorf("wrong about inequality")
 	}
+
+	// Equal compares values, not representations.
+	one := Element{1, 0, 0, 0, 0}
+	pPlusOne := Element{(1 << 51) - 18, (1 << 51) - 1, (1 << 51) - 1, (1 << 51) - 1, (1 << 51) - 1}
+	uncarried := Element{1 << 51, 0, 0, 0, 0}
+	carried := Element{0, 1, 0, 0, 0}
+	if one.Equal(&pPlusOne) != 1 || pPlusOne.Equal(&one) != 1 {
+		t.Errorf("wrong about equality of 1 and p + 1")
+	}
+	if uncarried.Equal(&carried) != 1 {
+		t.Errorf("wrong about equality of uncarried limbs")
+	}
+	if uncarried.Equal(&one) != 0 {
+		t.Errorf("wrong about inequality of uncarried limbs")
+	}
 }
 
 func TestInvert(t *testing.T) {
@@ -388,6 +403,15 @@ func TestSelectSwap(t *testing.T) {
 		t.Errorf("Select failed")
 	}
 
+	// Aliasing the receiver with either argument.
+	c, d = a, b
+	c.Select(&c, &b, 0)
+	d.Select(&a, &d, 1)
+	if c != b || d != a {
+		t.Errorf("Select failed with aliasing")
+	}
+	c, d = a, b
+
 	c.Swap(&d, 0)
 
 	if c.Equal(&a) != 1 || d.Equal(&b) != 1 {
@@ -0,0 +1,126 @@
+// Copyright (c) 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package edwards25519
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+// fuzzWideBytes returns the first 64 bytes of b, padded with zeroes if b is
+// shorter, for use with SetUniformBytes.
+func fuzzWideBytes(b []byte) []byte {
+	wide := make([]byte, 64)
+	copy(wide, b)
+	return wide
+}
+
+func FuzzScalarArithmetic(f *testing.F) {
+	l := Order()
+	seeds := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		new(big.Int).Sub(l, big.NewInt(1)),
+		l,
+		new(big.Int).Add(l, big.NewInt(1)),
+		new(big.Int).Lsh(big.NewInt(1), 252),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)),
+		new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 512), big.NewInt(1)),
+	}
+	for _, a := range seeds {
+		for _, b := range seeds {
+			f.Add(swapEndianness(a.FillBytes(make([]byte, 64))),
+				swapEndianness(b.FillBytes(make([]byte, 64))))
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, a, b []byte) {
+		a, b = fuzzWideBytes(a), fuzzWideBytes(b)
+		x, _ := new(Scalar).SetUniformBytes(a)
+		y, _ := new(Scalar).SetUniformBytes(b)
+
+		xBig := bigIntFromLittleEndianBytes(a)
+		xBig.Mod(xBig, l)
+		yBig := bigIntFromLittleEndianBytes(b)
+		yBig.Mod(yBig, l)
+		check := func(op string, got *Scalar, want *big.Int) {
+			t.Helper()
+			if !isReduced(got) {
+				t.Errorf("%s: result is not reduced: %x", op, got.Bytes())
+			}
+			want.Mod(want, l)
+			if gotBig := bigIntFromLittleEndianBytes(got.Bytes()); gotBig.Cmp(want) != 0 {
+				t.Errorf("%s: got %x, want %x", op, gotBig, want)
+			}
+		}
+
+		check("SetUniformBytes", x, new(big.Int).Set(xBig))
+		check("Add", new(Scalar).Add(x, y), new(big.Int).Add(xBig, yBig))
+		check("Subtract", new(Scalar).Subtract(x, y), new(big.Int).Sub(xBig, yBig))
+		check("Multiply", new(Scalar).Multiply(x, y), new(big.Int).Mul(xBig, yBig))
+		check("Negate", new(Scalar).Negate(x), new(big.Int).Neg(xBig))
+	})
+}
+
+func FuzzPointRoundTrip(f *testing.F) {
+	f.Add(generator.Bytes())
+	f.Add(identity.Bytes())
+	for _, enc := range lowOrderEncodings {
+		f.Add(decodeHex(enc))
+	}
+	// Non-canonical encodings, and encodings of points not on the curve.
+	f.Add(decodeHex("0100000000000000000000000000000000000000000000000000000000000080"))
+	f.Add(decodeHex("edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"))
+	f.Add(decodeHex("eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f"))
+	f.Add(decodeHex("0200000000000000000000000000000000000000000000000000000000000000"))
+	f.Add(decodeHex("ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"))
+
+	p := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	f.Fuzz(func(t *testing.T, in []byte) {
+		v := NewGeneratorPoint()
+		if _, err := v.SetBytes(in); err != nil {
+			if v.Equal(generator) != 1 {
+				t.Fatal("SetBytes modified the receiver on error")
+			}
+			if _, err := new(Point).SetBytes(in); err == nil {
+				t.Fatal("SetBytes rejection is not stable")
+			}
+			return
+		}
+		checkOnCurve(t, v)
+
+		out := v.Bytes()
+		w, err := new(Point).SetBytes(out)
+		if err != nil || w.Equal(v) != 1 || !bytes.Equal(w.Bytes(), out) {
+			t.Fatalf("canonical encoding %x does not round-trip", out)
+		}
+		if bytes.Equal(in, out) {
+			return
+		}
+
+		// SetBytes accepts exactly two kinds of non-canonical encodings: a
+		// y coordinate that is not reduced, and x = 0 with the sign bit set.
+		y := bigIntFromLittleEndianBytes(in)
+		y.SetBit(y, 255, 0)
+		xIsZero := v.Equal(new(Point).Negate(v)) == 1
+		switch {
+		case y.Cmp(p) >= 0:
+		case xIsZero && in[31]&0x80 != 0:
+		default:
+			t.Fatalf("SetBytes accepted %x, which encodes as %x", in, out)
+		}
+	})
+}
+
+func swapEndianness(b []byte) []byte {
+	for i := 0; i < len(b)/2; i++ {
+		b[i], b[len(b)-i-1] = b[len(b)-i-1], b[i]
+	}
+	return b
+}
@@ -71,7 +71,47 @@ func TestScalarAliasing(t *testing.T) {
 		return x == x1 && y == y1
 	}
 
+	checkAliasingThreeArgs := func(f func(v, x, y, z *Scalar) *Scalar, v, x, y, z Scalar) bool {
+		x1, y1, z1, v1 := x, y, z, Scalar{}
+
+		// Calculate a reference f(x, y, z) without aliasing.
+		if out := f(&v, &x, &y, &z); out != &v || !isReduced(out) {
+			return false
+		}
+
+		// Test aliasing each argument and the receiver.
+		v1 = x
+		if out := f(&v1, &v1, &y, &z); out != &v1 || v1 != v || !isReduced(out) {
+			return false
+		}
+		v1 = y
+		if out := f(&v1, &x, &v1, &z); out != &v1 || v1 != v || !isReduced(out) {
+			return false
+		}
+		v1 = z
+		if out := f(&v1, &x, &y, &v1); out != &v1 || v1 != v || !isReduced(out) {
+			return false
+		}
+
+		// Calculate a reference f(x, x, x) without aliasing.
+		if out := f(&v, &x, &x, &x); out != &v || !isReduced(out) {
+			return false
+		}
+
+		// Test aliasing all arguments and the receiver.
+		v1 = x
+		if out := f(&v1, &v1, &v1, &v1); out != &v1 || v1 != v || !isReduced(out) {
+			return false
+		}
+
+		// Ensure the arguments were not modified.
+		return x == x1 && y == y1 && z == z1
+	}
+
 	for name, f := range map[string]interface{}{
+		"MultiplyAdd": func(v, x, y, z Scalar) bool {
+			return checkAliasingThreeArgs((*Scalar).MultiplyAdd, v, x, y, z)
+		},
 		"Negate": func(v, x Scalar) bool {
 			return checkAliasingOneArg((*Scalar).Negate, v, x)
 		},
@@ -87,7 +87,12 @@ func (v *Point) ScalarMult(x *Scalar, q *Point) *Point {
 
 	var table projLookupTable
 	table.FromP3(q)
+	return v.scalarMultTable(x, &table)
+}
 
+// scalarMultTable sets v = x * Q, where table is the projLookupTable of Q, and
+// returns v. Execution time is independent of x.
+func (v *Point) scalarMultTable(x *Scalar, table *projLookupTable) *Point {
 	// Write x = sum(x_i * 16^i)
 	// so  x*Q = sum( Q*x_i*16^i )
 	//         = Q*x_0 + 16*(Q*x_1 + 16*( ... + Q*x_63) ... )
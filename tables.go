@@ -28,6 +28,12 @@ type nafLookupTable8 struct {
 	points [64]affineCached
 }
 
+// A dynamic lookup table for variable-base, variable-time scalar muls with a
+// NAF of any width between 2 and 8.
+type nafLookupTable struct {
+	points []projCached
+}
+
 // Constructors.
 
 // Builds a lookup table at runtime. Fast.
@@ -84,6 +90,24 @@ func (v *nafLookupTable8) FromP3(q *Point) {
 	}
 }
 
+// Builds a lookup table at runtime for a width-w NAF. Fast.
+func (v *nafLookupTable) FromP3(q *Point, w uint) {
+	if w < 2 || w > 8 {
+		panic("edwards25519: invalid NAF width")
+	}
+	// Goal: v.points[i] = (2*i+1)*Q, i.e., Q, 3Q, 5Q, ..., (2^(w-1)-1)Q
+	// This allows lookup of all the odd digits of a width-w NAF.
+	v.points = make([]projCached, 1<<(w-2))
+	v.points[0].FromP3(q)
+	q2 := Point{}
+	q2.Add(q, q)
+	tmpP3 := Point{}
+	tmpP1xP1 := projP1xP1{}
+	for i := 0; i < len(v.points)-1; i++ {
+		v.points[i+1].FromP3(tmpP3.fromP1xP1(tmpP1xP1.Add(&q2, &v.points[i])))
+	}
+}
+
 // Selectors.
 
 // Set dest to x*Q, where -8 <= x <= 8, in constant time.
@@ -127,3 +151,8 @@ func (v *nafLookupTable5) SelectInto(dest *projCached, x int8) {
 func (v *nafLookupTable8) SelectInto(dest *affineCached, x int8) {
 	*dest = v.points[x/2]
 }
+
+// Given odd x with 0 < x < 2^(w-1), return x*Q (in variable time).
+func (v *nafLookupTable) SelectInto(dest *projCached, x int8) {
+	*dest = v.points[x/2]
+}
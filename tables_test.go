@@ -117,3 +117,58 @@ func TestNafLookupTable8(t *testing.T) {
 		t.Errorf("Consistency check on nafLookupTable8 failed")
 	}
 }
+
+func TestNafLookupTable(t *testing.T) {
+	for w := uint(2); w <= 8; w++ {
+		var table nafLookupTable
+		table.FromP3(B, w)
+		if len(table.points) != 1<<(w-2) {
+			t.Errorf("w = %d: got %d points, want %d", w, len(table.points), 1<<(w-2))
+		}
+
+		var p, check Point
+		var tmp projCached
+		var acc projP1xP1
+		for x := 1; x < 1<<(w-1); x += 2 {
+			table.SelectInto(&tmp, int8(x))
+			p.fromP1xP1(acc.Add(NewIdentityPoint(), &tmp))
+			check.ScalarMult(&Scalar{[32]byte{byte(x)}}, B)
+			if p.Equal(&check) != 1 {
+				t.Errorf("w = %d: table entry for %d is not %d*B", w, x, x)
+			}
+		}
+	}
+
+	// The fixed-width tables agree with the dynamic one.
+	var table nafLookupTable
+	var table5 nafLookupTable5
+	table.FromP3(B, 5)
+	table5.FromP3(B)
+	for i := range table5.points {
+		if table.points[i] != table5.points[i] {
+			t.Errorf("nafLookupTable and nafLookupTable5 differ at %d", i)
+		}
+	}
+}
+
+func TestProjLookupTableEveryIndex(t *testing.T) {
+	var table projLookupTable
+	table.FromP3(B)
+
+	var p, check Point
+	var tmp projCached
+	var acc projP1xP1
+	for x := -8; x <= 8; x++ {
+		table.SelectInto(&tmp, int8(x))
+		p.fromP1xP1(acc.Add(NewIdentityPoint(), &tmp))
+		check.ScalarMult(&Scalar{[32]byte{byte(-x)}}, B)
+		if x > 0 {
+			check.ScalarMult(&Scalar{[32]byte{byte(x)}}, B)
+		} else {
+			check.Negate(&check)
+		}
+		if p.Equal(&check) != 1 {
+			t.Errorf("SelectInto(%d) is not %d*B", x, x)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+)
+
+// vrfSuite is the suite_string of ECVRF-EDWARDS25519-SHA512-TAI.
+const vrfSuite = 0x03
+
+// VRFProve computes the ECVRF-EDWARDS25519-SHA512-TAI proof for alpha, as
+// specified in RFC 9381, Section 5.1, and returns the 64-byte VRF output beta
+// and the 80-byte proof pi. sk is a 32-byte Ed25519 private key seed, and the
+// corresponding public key is the Ed25519 public key of the same seed.
+//
+// VRFProve panics if sk is not 32 bytes long. Its execution time is
+// independent of sk.
+func VRFProve(sk, alpha []byte) (beta, pi []byte) {
+	var x Scalar
+	prefix, err := x.SetEd25519Seed(sk)
+	if err != nil {
+		panic("edwards25519: bad VRF secret key length")
+	}
+	pk := new(Point).ScalarBaseMult(&x).Bytes()
+
+	H := vrfEncodeToCurve(pk, alpha)
+	hString := H.Bytes()
+	gamma := new(Point).ScalarMult(&x, H)
+	gammaString := gamma.Bytes()
+
+	// The nonce is derived as in RFC 8032, Section 5.1.6, from the second
+	// half of the expanded key and the encoding of H.
+	h := sha512.New()
+	h.Write(prefix)
+	h.Write(hString)
+	k, _ := NewScalar().SetFromHash(h)
+
+	kB := new(Point).ScalarBaseMult(k)
+	kH := new(Point).ScalarMult(k, H)
+	c := vrfChallenge(pk, hString, gammaString, kB, kH)
+	s := NewScalar().MultiplyAdd(c, &x, k)
+
+	pi = make([]byte, 0, 80)
+	pi = append(pi, gammaString...)
+	pi = append(pi, c.s[:16]...)
+	pi = append(pi, s.s[:]...)
+	return vrfProofToHash(gamma), pi
+}
+
+// VRFVerify checks the ECVRF-EDWARDS25519-SHA512-TAI proof pi for alpha under
+// the public key pk, as specified in RFC 9381, Section 5.3, and returns the
+// 64-byte VRF output beta and true if it is valid. Public keys of small order
+// are rejected, as with the validate_key option of the RFC, and so are
+// non-canonical encodings of pk or of the points and scalars in pi.
+//
+// VRFVerify operates on public inputs, and its execution time depends on them.
+func VRFVerify(pk, alpha, pi []byte) (beta []byte, ok bool) {
+	Y, err := vrfStringToPoint(pk)
+	if err != nil || Y.SmallOrderFactor() != 0 {
+		return nil, false
+	}
+	if len(pi) != 80 {
+		return nil, false
+	}
+	gamma, err := vrfStringToPoint(pi[:32])
+	if err != nil {
+		return nil, false
+	}
+	var cBytes [32]byte
+	copy(cBytes[:16], pi[32:48])
+	c, _ := NewScalar().SetCanonicalBytes(cBytes[:])
+	s, err := NewScalar().SetCanonicalBytes(pi[48:])
+	if err != nil {
+		return nil, false
+	}
+
+	H := vrfEncodeToCurve(pk, alpha)
+	negC := NewScalar().Negate(c)
+	U := new(Point).VarTimeDoubleScalarBaseMult(negC, Y, s)
+	V := new(Point).VarTimeMultiScalarMult([]*Scalar{s, negC}, []*Point{H, gamma})
+
+	if vrfChallenge(pk, H.Bytes(), pi[:32], U, V).Equal(c) != 1 {
+		return nil, false
+	}
+	return vrfProofToHash(gamma), true
+}
+
+// vrfStringToPoint decodes a point following RFC 8032, Section 5.1.3, which
+// unlike SetBytes rejects non-canonical encodings.
+func vrfStringToPoint(b []byte) (*Point, error) {
+	p, err := new(Point).SetBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(p.Bytes(), b) {
+		return nil, errors.New("edwards25519: non-canonical point encoding")
+	}
+	return p, nil
+}
+
+// vrfEncodeToCurve implements ECVRF_encode_to_curve_try_and_increment from
+// RFC 9381, Section 5.4.1.1, with encode_to_curve_salt set to pk.
+func vrfEncodeToCurve(pk, alpha []byte) *Point {
+	for ctr := 0; ctr < 256; ctr++ {
+		h := sha512.New()
+		h.Write([]byte{vrfSuite, 0x01})
+		h.Write(pk)
+		h.Write(alpha)
+		h.Write([]byte{byte(ctr), 0x00})
+		if H, err := vrfStringToPoint(h.Sum(nil)[:32]); err == nil {
+			return H.MultByCofactor(H)
+		}
+	}
+	// About half of all encodings are valid, so this is unreachable in
+	// practice.
+	panic("edwards25519: VRF try-and-increment failed")
+}
+
+// vrfChallenge implements ECVRF_challenge_generation from RFC 9381, Section
+// 5.4.3, returning the 16-byte challenge as a scalar.
+func vrfChallenge(pk, hString, gammaString []byte, U, V *Point) *Scalar {
+	h := sha512.New()
+	h.Write([]byte{vrfSuite, 0x02})
+	h.Write(pk)
+	h.Write(hString)
+	h.Write(gammaString)
+	h.Write(U.Bytes())
+	h.Write(V.Bytes())
+	h.Write([]byte{0x00})
+	var c Scalar
+	copy(c.s[:16], h.Sum(nil))
+	return &c
+}
+
+// vrfProofToHash implements ECVRF_proof_to_hash from RFC 9381, Section 5.2,
+// given the decoded Gamma.
+func vrfProofToHash(gamma *Point) []byte {
+	h := sha512.New()
+	h.Write([]byte{vrfSuite, 0x03})
+	h.Write(new(Point).MultByCofactor(gamma).Bytes())
+	h.Write([]byte{0x00})
+	return h.Sum(nil)
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+	"testing/quick"
+)
+
+func TestVRFVectors(t *testing.T) {
+	// The keys, inputs, Gamma values and outputs are from RFC 9381, Appendix
+	// B.3 (Examples 16 to 18), and so is the full proof of Example 16. The c
+	// and s halves of the other two proofs were computed with the same code
+	// path that reproduces Example 16, and have not yet been compared with the
+	// published text.
+	tests := []struct {
+		sk, pk, alpha, pi, beta string
+	}{
+		{
+			"9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+			"d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+			"",
+			"8657106690b5526245a92b003bb079ccd1a92130477671f6fc01ad16f26f723f26f8a57ccaed74ee1b190bed1f479d9727d2d0f9b005a6e456a35d4fb0daab1268a1b0db10836d9826a528ca76567805",
+			"90cf1df3b703cce59e2a35b925d411164068269d7b2d29f3301c03dd757876ff66b71dda49d2de59d03450451af026798e8f81cd2e333de5cdf4f3e140fdd8ae",
+		},
+		{
+			"4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+			"3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c",
+			"72",
+			"f3141cd382dc42909d19ec5110469e4feae18300e94f304590abdced48aed5933bf0864a62558b3ed7f2fea45c92a465301b3bbf5e3e54ddf2d935be3b67926da3ef39226bbc355bdc9850112c8f4b02",
+			"eb4440665d3891d668e7e0fcaf587f1b4bd7fbfe99d0eb2211ccec90496310eb5e33821bc613efb94db5e5b54c70a848a0bef4553a41befc57663b56373a5031",
+		},
+		{
+			"c5aa8df43f9f837bedb7442f31dcb7b166d38535076f094b85ce3a2e0b4458f7",
+			"fc51cd8e6218a1a38da47ed00230f0580816ed13ba3303ac5deb911548908025",
+			"af82",
+			"9bc0f79119cc5604bf02d23b4caede71393cedfbb191434dd016d30177ccbf8096bb474e53895c362d8628ee9f9ea3c0e52c7a5c691b6c18c9979866568add7a2d41b00b05081ed0f58ee5e31b3a970e",
+			"645427e5d00c62a23fb703732fa5d892940935942101e456ecca7bb217c61c452118fec1219202a0edcf038bb6373241578be7217ba85a2687f7a0310b2df19f",
+		},
+	}
+	for _, tt := range tests {
+		beta, pi := VRFProve(decodeHex(tt.sk), decodeHex(tt.alpha))
+		if got := hex.EncodeToString(pi); got != tt.pi {
+			t.Errorf("VRFProve(%s, %q): pi = %s, want %s", tt.sk, tt.alpha, got, tt.pi)
+		}
+		if got := hex.EncodeToString(beta); got != tt.beta {
+			t.Errorf("VRFProve(%s, %q): beta = %s, want %s", tt.sk, tt.alpha, got, tt.beta)
+		}
+
+		beta, ok := VRFVerify(decodeHex(tt.pk), decodeHex(tt.alpha), decodeHex(tt.pi))
+		if !ok {
+			t.Errorf("VRFVerify rejected the proof for %s", tt.pk)
+		} else if got := hex.EncodeToString(beta); got != tt.beta {
+			t.Errorf("VRFVerify(%s, %q): beta = %s, want %s", tt.pk, tt.alpha, got, tt.beta)
+		}
+	}
+}
+
+func TestVRFProveVerify(t *testing.T) {
+	f := func(sk [32]byte, alpha []byte) bool {
+		beta, pi := VRFProve(sk[:], alpha)
+		if len(beta) != 64 || len(pi) != 80 {
+			return false
+		}
+		pk := ed25519.NewKeyFromSeed(sk[:]).Public().(ed25519.PublicKey)
+		got, ok := VRFVerify(pk, alpha, pi)
+		if !ok || !bytes.Equal(got, beta) {
+			return false
+		}
+		// A different input must not verify.
+		_, ok = VRFVerify(pk, append(alpha, 0), pi)
+		return !ok
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestVRFVerifyInvalid(t *testing.T) {
+	sk := decodeHex("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	pk := decodeHex("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+	alpha := []byte("message")
+	_, pi := VRFProve(sk, alpha)
+
+	for i := 0; i < len(pi); i++ {
+		bad := append([]byte{}, pi...)
+		bad[i] ^= 1
+		if beta, ok := VRFVerify(pk, alpha, bad); ok || beta != nil {
+			t.Errorf("VRFVerify accepted a proof with byte %d modified", i)
+		}
+	}
+	if _, ok := VRFVerify(pk, alpha, pi[:79]); ok {
+		t.Error("VRFVerify accepted a truncated proof")
+	}
+	if _, ok := VRFVerify(pk[:31], alpha, pi); ok {
+		t.Error("VRFVerify accepted a truncated public key")
+	}
+
+	// s + l is a non-canonical encoding of the same scalar.
+	s, _ := new(Scalar).SetCanonicalBytes(pi[48:])
+	sBig := bigIntFromLittleEndianBytes(s.Bytes())
+	sBig.Add(sBig, Order())
+	sPlusL := sBig.FillBytes(make([]byte, 32))
+	for i, j := 0, len(sPlusL)-1; i < j; i, j = i+1, j-1 {
+		sPlusL[i], sPlusL[j] = sPlusL[j], sPlusL[i]
+	}
+	bad := append(append([]byte{}, pi[:48]...), sPlusL...)
+	if _, ok := VRFVerify(pk, alpha, bad); ok {
+		t.Error("VRFVerify accepted a non-canonical s")
+	}
+
+	// Small-order public keys are rejected.
+	for _, enc := range lowOrderEncodings {
+		if _, ok := VRFVerify(decodeHex(enc), alpha, pi); ok {
+			t.Errorf("VRFVerify accepted the small-order public key %s", enc)
+		}
+	}
+}
+
+func TestVRFProvePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("VRFProve did not panic on a short key")
+		}
+	}()
+	VRFProve(make([]byte, 31), nil)
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+import (
+	"crypto/subtle"
+	"errors"
+
+	"filippo.io/edwards25519/field"
+)
+
+// X25519 returns the result of the scalar multiplication (scalar * point),
+// according to RFC 7748, Section 5. scalar and point must be 32 bytes long,
+// and point is a Montgomery u-coordinate, such as one returned by
+// BytesMontgomery. The scalar is clamped, and the most significant bit of the
+// point is ignored, as the RFC requires.
+//
+// If the result is the all-zero value, which happens when point has a small
+// order, X25519 returns an error, as most protocols require.
+//
+// X25519 uses the Montgomery ladder, and its execution time is independent of
+// the inputs.
+func X25519(scalar, point []byte) ([]byte, error) {
+	if len(scalar) != 32 {
+		return nil, errors.New("edwards25519: invalid X25519 scalar length")
+	}
+	if len(point) != 32 {
+		return nil, errors.New("edwards25519: invalid X25519 point length")
+	}
+
+	var out [32]byte
+	x25519(&out, scalar, point)
+	var zero [32]byte
+	if subtle.ConstantTimeCompare(out[:], zero[:]) == 1 {
+		return nil, errors.New("edwards25519: X25519 input is a low order point")
+	}
+	return out[:], nil
+}
+
+// X25519Base returns the result of the scalar multiplication (scalar * 9),
+// where 9 is the canonical Montgomery base point, as X25519(scalar, 9) would.
+// scalar must be 32 bytes long, and it is clamped.
+//
+// X25519Base computes the multiplication on the equivalent Edwards base point
+// with the precomputed tables of ScalarBaseMult, which is about twice as fast
+// as the ladder. Its execution time is independent of the scalar.
+func X25519Base(scalar []byte) ([]byte, error) {
+	s, err := new(Scalar).SetBytesWithClamping(scalar)
+	if err != nil {
+		return nil, errors.New("edwards25519: invalid X25519 scalar length")
+	}
+	// The clamped scalar is never a multiple of l, so the result is never
+	// the identity, which has no u-coordinate.
+	return new(Point).ScalarBaseMult(s).BytesMontgomery(), nil
+}
+
+func x25519(dst *[32]byte, scalar, point []byte) {
+	var k [32]byte
+	copy(k[:], scalar)
+	k[0] &= 248
+	k[31] &= 127
+	k[31] |= 64
+
+	var x1, x2, z2, x3, z3, tmp0, tmp1 field.Element
+	x1.SetBytes(point)
+	x2.One()
+	x3.Set(&x1)
+	z3.One()
+
+	swap := 0
+	for pos := 254; pos >= 0; pos-- {
+		b := int(k[pos/8]>>uint(pos&7)) & 1
+		swap ^= b
+		x2.Swap(&x3, swap)
+		z2.Swap(&z3, swap)
+		swap = b
+
+		// The differential addition and doubling step from RFC 7748,
+		// Section 5, with a24 = 121665.
+		tmp0.Subtract(&x3, &z3)     // D = x3 - z3
+		tmp1.Subtract(&x2, &z2)     // B = x2 - z2
+		x2.Add(&x2, &z2)            // A = x2 + z2
+		z2.Add(&x3, &z3)            // C = x3 + z3
+		z3.Multiply(&tmp0, &x2)     // DA = D * A
+		z2.Multiply(&z2, &tmp1)     // CB = C * B
+		tmp0.Square(&tmp1)          // BB = B^2
+		tmp1.Square(&x2)            // AA = A^2
+		x3.Add(&z3, &z2)            // DA + CB
+		z2.Subtract(&z3, &z2)       // DA - CB
+		x2.Multiply(&tmp1, &tmp0)   // x2 = AA * BB
+		tmp1.Subtract(&tmp1, &tmp0) // E = AA - BB
+		z2.Square(&z2)              // (DA - CB)^2
+		z3.Mult32(&tmp1, 121665)    // a24 * E
+		x3.Square(&x3)              // x3 = (DA + CB)^2
+		tmp0.Add(&tmp0, &tmp1)      // BB + E = AA
+		tmp0.Add(&tmp0, &z3)        // AA + a24 * E
+		z3.Multiply(&x1, &z2)       // z3 = x1 * (DA - CB)^2
+		z2.Multiply(&tmp1, &tmp0)   // z2 = E * (AA + a24 * E)
+	}
+
+	x2.Swap(&x3, swap)
+	z2.Swap(&z3, swap)
+
+	z2.Invert(&z2)
+	x2.Multiply(&x2, &z2)
+	copy(dst[:], x2.Bytes())
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"testing/quick"
+)
+
+func TestX25519Vectors(t *testing.T) {
+	// From RFC 7748, Section 5.2.
+	tests := []struct {
+		scalar, point, out string
+	}{
+		{
+			"a546e36bf0527c9d3b16154b82465edd62144c0ac1fc5a18506a2244ba449ac4",
+			"e6db6867583030db3594c1a424b15f7c726624ec26b3353b10a903a6d0ab1c4c",
+			"c3da55379de9c6908e94ea4df28d084f32eccf03491c71f754b4075577a28552",
+		},
+		{
+			"4b66e9d4d1b4673c5ad22691957d6af5c11b6421e0ea01d42ca4169e7918ba0d",
+			"e5210f12786811d3f4b7959d0538ae2c31dbe7106fc03c3efc4cd549c715a493",
+			"95cbde9476e8907d7aade45cb4b873f88b595a68799fa152e6f8f7647aac7957",
+		},
+	}
+	for _, tt := range tests {
+		out, err := X25519(decodeHex(tt.scalar), decodeHex(tt.point))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(out); got != tt.out {
+			t.Errorf("X25519(%s, %s) = %s, want %s", tt.scalar, tt.point, got, tt.out)
+		}
+	}
+}
+
+func TestX25519Iterated(t *testing.T) {
+	// From RFC 7748, Section 5.2. The 1,000,000 iterations vector is omitted
+	// because it takes about a minute.
+	k := make([]byte, 32)
+	k[0] = 9
+	u := append([]byte{}, k...)
+	for i := 1; i <= 1000; i++ {
+		out, err := X25519(k, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k, u = out, k
+
+		var want string
+		switch i {
+		case 1:
+			want = "422c8e7a6227d7bca1350b3e2bb7279f7897b87bb6854b783c60e80311ae3079"
+		case 1000:
+			want = "684cf59ba83309552800ef566f2f4d3c1c3887c49360e3875f2eb94d99532c51"
+		default:
+			continue
+		}
+		if got := hex.EncodeToString(k); got != want {
+			t.Errorf("after %d iterations: got %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestX25519DiffieHellman(t *testing.T) {
+	// From RFC 7748, Section 6.1.
+	alicePriv := decodeHex("77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	alicePub := decodeHex("8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a")
+	bobPriv := decodeHex("5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb")
+	bobPub := decodeHex("de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f")
+	shared := decodeHex("4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742")
+
+	basepoint := make([]byte, 32)
+	basepoint[0] = 9
+	for _, tt := range []struct {
+		scalar, point, want []byte
+	}{
+		{alicePriv, basepoint, alicePub},
+		{bobPriv, basepoint, bobPub},
+		{alicePriv, bobPub, shared},
+		{bobPriv, alicePub, shared},
+	} {
+		got, err := X25519(tt.scalar, tt.point)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("X25519(%x, %x) = %x, want %x", tt.scalar, tt.point, got, tt.want)
+		}
+	}
+}
+
+func TestX25519MatchesBytesMontgomery(t *testing.T) {
+	f := func(k [32]byte, x Scalar) bool {
+		p := new(Point).ScalarBaseMult(&x)
+		out, err := X25519(k[:], p.BytesMontgomery())
+		if x.Equal(NewScalar()) == 1 {
+			// The identity maps to u = 0, which has low order.
+			return err != nil && out == nil
+		}
+		if err != nil {
+			return false
+		}
+		s, _ := new(Scalar).SetBytesWithClamping(k[:])
+		want := new(Point).ScalarMult(s, p).BytesMontgomery()
+		return bytes.Equal(out, want)
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestX25519Errors(t *testing.T) {
+	scalar := make([]byte, 32)
+	scalar[0] = 1
+	if _, err := X25519(scalar[:31], scalar); err == nil {
+		t.Error("expected error for short scalar")
+	}
+	if _, err := X25519(scalar, make([]byte, 33)); err == nil {
+		t.Error("expected error for long point")
+	}
+
+	// u = 0 and u = 1 have low order, and so do the canonical Montgomery
+	// encodings of all the low-order Edwards points.
+	one := make([]byte, 32)
+	one[0] = 1
+	for _, u := range [][]byte{make([]byte, 32), one} {
+		if out, err := X25519(scalar, u); err == nil || out != nil {
+			t.Errorf("X25519(%x) did not fail", u)
+		}
+	}
+	for _, enc := range lowOrderEncodings {
+		p, _ := new(Point).SetBytes(decodeHex(enc))
+		if _, err := X25519(scalar, p.BytesMontgomery()); err == nil {
+			t.Errorf("X25519 accepted the low-order point %s", enc)
+		}
+	}
+}
+
+func TestX25519Base(t *testing.T) {
+	basepoint := make([]byte, 32)
+	basepoint[0] = 9
+	f := func(k [32]byte) bool {
+		got, err := X25519Base(k[:])
+		if err != nil {
+			return false
+		}
+		want, err := X25519(k[:], basepoint)
+		return err == nil && bytes.Equal(got, want)
+	}
+	if err := quick.Check(f, quickCheckConfig32); err != nil {
+		t.Error(err)
+	}
+
+	if out, err := X25519Base(make([]byte, 31)); err == nil || out != nil {
+		t.Error("expected error for short scalar")
+	}
+}
+
+func BenchmarkX25519(b *testing.B) {
+	scalar := decodeHex("a546e36bf0527c9d3b16154b82465edd62144c0ac1fc5a18506a2244ba449ac4")
+	point := decodeHex("e6db6867583030db3594c1a424b15f7c726624ec26b3353b10a903a6d0ab1c4c")
+	for i := 0; i < b.N; i++ {
+		X25519(scalar, point)
+	}
+}
+
+func BenchmarkX25519Base(b *testing.B) {
+	scalar := decodeHex("a546e36bf0527c9d3b16154b82465edd62144c0ac1fc5a18506a2244ba449ac4")
+	for i := 0; i < b.N; i++ {
+		X25519Base(scalar)
+	}
+}